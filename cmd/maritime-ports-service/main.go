@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
+	// Embed the IANA time zone database, since port timezones are validated
+	// against it and the runtime image does not ship one.
+	_ "time/tzdata"
 
 	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
 	"github.com/powerslider/maritime-ports-service/pkg/transport/server"
@@ -12,7 +16,8 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/powerslider/maritime-ports-service/pkg/configs"
 	"github.com/powerslider/maritime-ports-service/pkg/handlers"
-	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+	"github.com/powerslider/maritime-ports-service/pkg/seeding"
+	"github.com/powerslider/maritime-ports-service/pkg/storage"
 )
 
 // @title Maritime Ports Service API
@@ -29,21 +34,19 @@ import (
 // @host 0.0.0.0:8080
 // @BasePath /
 func main() {
-	var err error
-
 	setEnvironment()
 
-	portsStore := memory.NewPortsRepository()
-	loader := portsmanaging.NewJSONLoader(portsStore)
+	ctx := context.Background()
+	conf := configs.InitializeConfig()
 
-	err = loader.LoadJSONFile("./fixtures/ports.json")
+	portsStore, closeStore, err := storage.InitializePortsStore(conf)
 	if err != nil {
-		log.Fatalf("cannot seed service database with ports data: %v", err)
+		log.Fatalf("cannot initialize ports storage: %v", err)
 	}
 
-	ctx := context.Background()
-
-	conf := configs.InitializeConfig()
+	if err = seedPorts(ctx, portsStore, conf); err != nil {
+		log.Fatalf("cannot seed service database with ports data: %v", err)
+	}
 
 	portsService := portsmanaging.NewService(portsStore)
 
@@ -51,11 +54,52 @@ func main() {
 	router = handlers.InitializeHandlers(conf, router, portsService)
 
 	s := server.NewServer(conf, router)
-	if err = s.Run(ctx); err != nil {
+	errRun := s.Run(ctx)
+	errClose := closeStore()
+
+	if err = errors.Join(errRun, errClose); err != nil {
 		log.Fatal(err.Error())
 	}
 }
 
+// seedPorts loads the ports of the configured seed sources into the store. A persistent
+// store is marked as seeded once seeding succeeds and is left untouched afterwards, so
+// that modifications made through the API are not overwritten on restart, while a seed
+// which failed part-way is completed on the next start.
+func seedPorts(ctx context.Context, portsStore portsmanaging.PortsStore, conf *configs.Config) error {
+	marker, persistent := portsStore.(storage.SeedMarker)
+	if persistent {
+		seeded, err := marker.Seeded()
+		if err != nil {
+			return err
+		}
+
+		if seeded {
+			return nil
+		}
+	}
+
+	report, err := seeding.InitializeSeeder(conf, portsStore).Seed(ctx, conf.Seed.Sources)
+
+	if conf.Seed.ReportPath != "" {
+		err = errors.Join(err, report.WriteFile(conf.Seed.ReportPath))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(report.Rejected) > 0 {
+		log.Printf("seeded %d ports, rejected %d malformed or invalid ports", report.Loaded, len(report.Rejected))
+	}
+
+	if persistent {
+		return marker.MarkSeeded()
+	}
+
+	return nil
+}
+
 func setEnvironment() {
 	_, foundHost := os.LookupEnv("SERVER_HOST")
 	_, foundPort := os.LookupEnv("SERVER_PORT")
@@ -26,26 +26,179 @@ const docTemplate = `{
     "paths": {
         "/api/v1/ports": {
             "get": {
-                "description": "Get all ports stored in the system.",
+                "description": "Get all ports stored in the system in a stable order, optionally paginated.\nPages are addressed either by ` + "`" + `offset` + "`" + ` or by the ` + "`" + `next_cursor` + "`" + ` of the previous page.\nField filters match exact values case-sensitively, or prefixes case-insensitively when their\nvalue ends with ` + "`" + `*` + "`" + `.\nResponses can be revalidated with ` + "`" + `If-None-Match` + "`" + ` or ` + "`" + `If-Modified-Since` + "`" + `, which yield\n304 Not Modified as long as no port has changed.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
                     "ports"
                 ],
                 "summary": "Get all ports stored in the system.",
-                "responses": {}
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of ports in the page (default 100, capped at 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of ports to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, name, country, city), prefixed with '-' for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Country filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Province filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "province",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "timezone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Region filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UN/LOCODE filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "unloc",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Code filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Alias filter (case-sensitive), or a case-insensitive prefix ending with '*'",
+                        "name": "alias",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coordinates format: array ([lon, lat], default) or object ({lat, lon})",
+                        "name": "coords",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tags of cached responses",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified time of a cached response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the revision of all ports"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last modification of any port"
+                            },
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Caching policy"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the revision of all ports"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last modification of any port"
+                            },
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Caching policy"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
             },
             "post": {
-                "description": "Create a new port or update an existing one.",
+                "description": "Create a new port or update an existing one.\nThe ` + "`" + `id` + "`" + ` and all ` + "`" + `unlocs` + "`" + ` must be valid UN/LOCODEs and ` + "`" + `name` + "`" + ` and ` + "`" + `country` + "`" + ` are required.\nThe ` + "`" + `coordinates` + "`" + `, when known, are a [longitude, latitude] pair and the ` + "`" + `timezone` + "`" + `,\nwhen known, is an IANA time zone name. All violations are reported at once.\nConcurrent modifications are detected by passing the ETag of the port as ` + "`" + `If-Match` + "`" + `.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
                     "ports"
@@ -60,20 +213,403 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/portsmanaging.MaritimePort"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tags of the expected port versions, or * for any existing port",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "* to only create a port which does not exist yet",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
-                "responses": {}
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the port version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Port version does not match",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
             }
         },
-        "/api/v1/ports/{id}": {
+        "/api/v1/ports/export": {
             "get": {
-                "description": "Get an existing port by ID.",
+                "description": "Export all ports ordered by ID either as a JSON object of ports keyed by their IDs,\nin the same shape as the ports fixtures, or as newline-delimited JSON with a single\nport including its ID per line. The response is streamed.",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Export all ports.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Data format: json (default) or ndjson",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ports data",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ports/import": {
+            "post": {
+                "description": "Import ports given either as a JSON object of ports keyed by their IDs, like the ports\nfixtures, or as newline-delimited JSON with a single port including its ID per line.\nThe request body is streamed into the store port by port, creating new ports and\nupdating existing ones. Importing stops at the first invalid port, keeping the ports\nimported so far.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Import ports.",
+                "parameters": [
+                    {
+                        "description": "Ports data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported content type",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Malformed data or invalid port",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ports/nearest": {
+            "get": {
+                "description": "Get the k ports nearest to a location ordered by ascending great-circle distance in kilometers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Get the ports nearest to a location.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Latitude in degrees",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude in degrees",
+                        "name": "lon",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of ports to return (default 10, max 100)",
+                        "name": "k",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum distance in kilometers",
+                        "name": "max_km",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coordinates format: array ([lon, lat], default) or object ({lat, lon})",
+                        "name": "coords",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ports/search": {
+            "get": {
+                "description": "Search ports by name, city, province, alias and UN/LOCODE. Terms are matched regardless\nof case and diacritics, as prefixes and with tolerance for typos. Ports are returned\ntogether with their relevance score in descending order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Search ports by free text.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text of at most 200 characters and 10 terms",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of ports to return (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coordinates format: array ([lon, lat], default) or object ({lat, lon})",
+                        "name": "coords",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ports/suggest": {
+            "get": {
+                "description": "Suggest ports with a name, alias or UN/LOCODE having a word starting with a prefix,\nmatched regardless of case and diacritics. Suggestions are ordered by the matching value.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Suggest ports while typing.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prefix typed so far",
+                        "name": "prefix",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of suggestions to return (default 10, max 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ports/within": {
+            "get": {
+                "description": "Get the ports inside either a bounding box given by ` + "`" + `bbox` + "`" + ` or a circle given by ` + "`" + `center` + "`" + `\nand ` + "`" + `radius_km` + "`" + `. A bounding box with a minimum longitude greater than its maximum longitude\ncrosses the antimeridian. Ports in a bounding box are ordered by ID and ports in a circle\nare returned together with their great-circle distance to the center in ascending order.",
+                "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Get the ports inside a bounding box or a circle.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bounding box as minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Circle center as lat,lon",
+                        "name": "center",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Circle radius in kilometers",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coordinates format: array ([lon, lat], default) or object ({lat, lon})",
+                        "name": "coords",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ports/{id}": {
+            "get": {
+                "description": "Get an existing port by ID.\nResponses can be revalidated with ` + "`" + `If-None-Match` + "`" + ` or ` + "`" + `If-Modified-Since` + "`" + `, which yield\n304 Not Modified as long as the port has not changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
                 "tags": [
                     "ports"
                 ],
@@ -85,13 +621,399 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coordinates format: array ([lon, lat], default) or object ({lat, lon})",
+                        "name": "coords",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tags of cached port versions",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified time of a cached response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the port version"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last modification of any port"
+                            },
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Caching policy"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the port version"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last modification of any port"
+                            },
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Caching policy"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Port not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an existing port by ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Delete an existing port by ID.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MaritimePort ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tags of the expected port versions, or * for any existing port",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Port not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Port version does not match",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially update an existing port by ID with either a JSON Merge Patch (RFC 7396)\nor a JSON Patch (RFC 6902) document, selected by the request content type.\nRemoving a member (` + "`" + `null` + "`" + ` in a merge patch, ` + "`" + `remove` + "`" + ` in a JSON patch) clears it:\n` + "`" + `alias` + "`" + `, ` + "`" + `regions` + "`" + ` and ` + "`" + `unlocs` + "`" + ` become empty lists, ` + "`" + `coordinates` + "`" + ` becomes null\nand string members like ` + "`" + `code` + "`" + ` become empty. The ` + "`" + `id` + "`" + ` member cannot be changed.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Partially update an existing port by ID.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MaritimePort ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Patch document",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coordinates format: array ([lon, lat], default) or object ({lat, lon})",
+                        "name": "coords",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tags of the expected port versions, or * for any existing port",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
-                "responses": {}
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the port version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Port not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Patch conflicts with the port",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Port version does not match",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported content type",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ports:batch": {
+            "post": {
+                "description": "Create or update a batch of ports given either as an array of ports or as an object\nof ports keyed by their IDs, like the ports fixtures. Ports are validated like single\nports and applied in order, each of them independently unless ` + "`" + `atomic` + "`" + ` is set, in which\ncase either all of them are applied or, responding with 422, none of them.\nThe outcome of every port is listed in the order of the request, ports which cannot be\ndecoded failing like invalid ones. The request body must not be larger than 8 MiB.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ports"
+                ],
+                "summary": "Create or update a batch of ports.",
+                "parameters": [
+                    {
+                        "description": "Batch of MaritimePort entries",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/portsmanaging.MaritimePort"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply all ports or none of them",
+                        "name": "atomic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Atomic batch not applied",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
             }
         }
     },
     "definitions": {
+        "handlers.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "name is required"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portsmanaging.Violation"
+                    }
+                },
+                "etag": {
+                    "type": "string",
+                    "example": "\"1\""
+                },
+                "id": {
+                    "type": "string",
+                    "example": "AEAJM"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "updated",
+                        "failed",
+                        "skipped"
+                    ],
+                    "example": "created"
+                }
+            }
+        },
+        "handlers.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchItemResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "handlers.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "k must be between 1 and 100"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portsmanaging.Violation"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/ports/nearest"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 422
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Unprocessable Entity"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
         "portsmanaging.MaritimePort": {
             "type": "object",
             "properties": {
@@ -141,6 +1063,17 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "portsmanaging.Violation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
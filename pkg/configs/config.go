@@ -5,10 +5,36 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// StorageTypeMemory selects the volatile in-memory ports storage.
+	StorageTypeMemory = "memory"
+	// StorageTypeFile selects the persistent file-backed ports storage.
+	StorageTypeFile = "file"
+)
+
 // Config represents all HTTP server configuration options.
 type Config struct {
-	Host string `env:"SERVER_HOST"`
-	Port int    `env:"SERVER_PORT"`
+	Host         string `env:"SERVER_HOST"`
+	Port         int    `env:"SERVER_PORT"`
+	CacheControl string `env:"CACHE_CONTROL,default=no-cache"`
+	Storage      StorageConfig
+	Seed         SeedConfig
+}
+
+// StorageConfig represents all ports storage configuration options.
+type StorageConfig struct {
+	Type              string `env:"STORAGE_TYPE,default=memory"`
+	Dir               string `env:"STORAGE_DIR,default=./data"`
+	SnapshotThreshold int    `env:"STORAGE_SNAPSHOT_THRESHOLD,default=1000"`
+}
+
+// SeedConfig represents all ports seeding configuration options.
+type SeedConfig struct {
+	Sources     []string `env:"SEED_SOURCES,default=./fixtures/ports.json"`
+	Lenient     bool     `env:"SEED_LENIENT,default=false"`
+	ErrorBudget int      `env:"SEED_ERROR_BUDGET,default=0"`
+	ReportPath  string   `env:"SEED_REPORT_PATH"`
+	Workers     int      `env:"SEED_WORKERS,default=1"`
 }
 
 // NewConfig constructs a new instance of Config via decoding
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// maxBatchBytes is the maximum size of the request body of a batch of ports.
+const maxBatchBytes = 8 << 20
+
+// BatchResponse represents the outcome of upserting a batch of ports.
+type BatchResponse struct {
+	Created int                `json:"created" example:"1"`
+	Updated int                `json:"updated" example:"0"`
+	Failed  int                `json:"failed" example:"0"`
+	Skipped int                `json:"skipped" example:"0"`
+	Results []*BatchItemResult `json:"results"`
+}
+
+// BatchItemResult represents the outcome of upserting a single port of a batch. Results
+// are listed in the order of the ports in the request.
+type BatchItemResult struct {
+	ID     string                     `json:"id" example:"AEAJM"`
+	Status portsmanaging.UpsertStatus `json:"status" example:"created" enums:"created,updated,failed,skipped"`
+	ETag   string                     `json:"etag,omitempty" example:"\"1\""`
+	Detail string                     `json:"detail,omitempty" example:"name is required"`
+	Errors []portsmanaging.Violation  `json:"errors,omitempty"`
+}
+
+// newBatchResponse summarizes the results of upserting a batch of ports.
+func newBatchResponse(results []*portsmanaging.UpsertResult) *BatchResponse {
+	resp := &BatchResponse{Results: make([]*BatchItemResult, 0, len(results))}
+
+	for _, res := range results {
+		item := &BatchItemResult{ID: res.ID, Status: res.Status}
+
+		switch res.Status {
+		case portsmanaging.UpsertCreated:
+			resp.Created++
+		case portsmanaging.UpsertUpdated:
+			resp.Updated++
+		case portsmanaging.UpsertFailed:
+			resp.Failed++
+		case portsmanaging.UpsertSkipped:
+			resp.Skipped++
+		}
+
+		if res.Port != nil {
+			item.ETag = etag(res.Port.Version)
+		}
+
+		if res.Err != nil {
+			item.Detail = res.Err.Error()
+			item.Errors = portsmanaging.Violations(res.Err)
+		}
+
+		resp.Results = append(resp.Results, item)
+	}
+
+	return resp
+}
+
+// decodeBatch decodes a batch of ports given either as a JSON array of ports or as a JSON
+// object of ports keyed by their IDs, like the ports fixtures. The ports keep their order.
+// Every port is decoded on its own, so that a port which cannot be decoded, e.g. because
+// of a member of the wrong type, is returned as a failed item instead of failing the batch.
+func decodeBatch(r io.Reader) ([]*portsmanaging.BatchItem, error) {
+	dec := json.NewDecoder(r)
+
+	start, err := dec.Token()
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "could not unmarshal request body")
+	}
+
+	if start != json.Delim('[') && start != json.Delim('{') {
+		return nil, fmt.Errorf("request body must be a JSON array or object of ports")
+	}
+
+	batch := make([]*portsmanaging.BatchItem, 0)
+
+	for dec.More() {
+		var key json.Token
+
+		if start == json.Delim('{') {
+			if key, err = dec.Token(); err != nil {
+				return nil, pkgErrors.Wrap(err, "could not unmarshal request body")
+			}
+		}
+
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return nil, pkgErrors.Wrap(err, "could not unmarshal request body")
+		}
+
+		item := decodeBatchItem(raw)
+		if key != nil {
+			item.Port.ID = fmt.Sprint(key)
+		}
+
+		batch = append(batch, item)
+	}
+
+	if _, err = dec.Token(); err != nil {
+		return nil, pkgErrors.Wrap(err, "could not unmarshal request body")
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("request body must contain a single JSON array or object")
+	}
+
+	return batch, nil
+}
+
+// decodeBatchItem decodes a single port of a batch, marking data which cannot be decoded
+// as an ErrValidation error.
+func decodeBatchItem(raw json.RawMessage) *portsmanaging.BatchItem {
+	var p portsmanaging.MaritimePort
+
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return &portsmanaging.BatchItem{
+			Port: &p,
+			Err:  portsmanaging.WithKind(portsmanaging.ErrValidation, pkgErrors.Wrap(err, "could not unmarshal port")),
+		}
+	}
+
+	return &portsmanaging.BatchItem{Port: &p}
+}
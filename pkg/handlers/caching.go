@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// validators represents the validators of a cacheable representation, i.e. its
+// entity tag and the time it was last modified.
+type validators struct {
+	etag         string
+	lastModified time.Time
+}
+
+// revisionETag returns the strong entity tag of a revision of all ports. It includes the
+// modification time as well, so that tags are not reused after a restart of the service.
+func revisionETag(rev portsmanaging.Revision) string {
+	return fmt.Sprintf(`"r%d-%x"`, rev.Number, rev.ModifiedAt.UnixNano())
+}
+
+// notModified reports whether the conditional headers of a GET request are satisfied
+// by v, i.e. whether the client already has the current representation. If-None-Match
+// takes precedence over If-Modified-Since, as required by RFC 9110.
+func notModified(r *http.Request, v validators) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, v.etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+
+	return !v.lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches reports whether the list of entity tags of an If-None-Match header contains
+// tag, using the weak comparison.
+func etagMatches(header string, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeValidators sets the validators and the caching policy of a response.
+func (h *PortsHandler) writeValidators(rw http.ResponseWriter, v validators) {
+	rw.Header().Set("ETag", v.etag)
+	rw.Header().Set("Last-Modified", v.lastModified.UTC().Format(http.TimeFormat))
+
+	if h.CacheControl != "" {
+		rw.Header().Set("Cache-Control", h.CacheControl)
+	}
+}
+
+// handleNotModified responds with 304 Not Modified and reports true if the client already
+// has the representation described by v.
+func (h *PortsHandler) handleNotModified(rw http.ResponseWriter, r *http.Request, v validators) bool {
+	if !notModified(r, v) {
+		return false
+	}
+
+	h.writeValidators(rw, v)
+	rw.WriteHeader(http.StatusNotModified)
+
+	return true
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// coordsFormat selects how port coordinates are represented in responses.
+type coordsFormat int
+
+const (
+	// coordsArray represents coordinates as [longitude, latitude] arrays.
+	coordsArray coordsFormat = iota
+	// coordsObject represents coordinates as {"lat": ..., "lon": ...} objects.
+	coordsObject
+)
+
+// geoPointObject is a portsmanaging.GeoPoint without its array JSON encoding.
+type geoPointObject portsmanaging.GeoPoint
+
+// parseCoordsFormat maps the optional 'coords' query param to a coordsFormat.
+func parseCoordsFormat(query url.Values) (coordsFormat, error) {
+	switch v := query.Get("coords"); v {
+	case "", "array":
+		return coordsArray, nil
+	case "object":
+		return coordsObject, nil
+	default:
+		return 0, newParamError(
+			"coords", fmt.Sprintf("query param 'coords' must be either 'array' or 'object', got '%s'", v))
+	}
+}
+
+// coordsView is a portsmanaging.GeoPoint encoded in a given coordsFormat.
+type coordsView struct {
+	point  portsmanaging.GeoPoint
+	format coordsFormat
+}
+
+// MarshalJSON encodes the point either as a [longitude, latitude] array or as a
+// {"lat": ..., "lon": ...} object.
+func (c coordsView) MarshalJSON() ([]byte, error) {
+	if c.format == coordsObject {
+		return json.Marshal(geoPointObject(c.point))
+	}
+
+	return json.Marshal(c.point)
+}
+
+// portView is a portsmanaging.MaritimePort as represented in responses, with its
+// coordinates encoded in the requested coordsFormat.
+type portView struct {
+	*portsmanaging.MaritimePort
+	Coordinates *coordsView `json:"coordinates"`
+}
+
+// newPortView is a constructor function for portView.
+func newPortView(p *portsmanaging.MaritimePort, format coordsFormat) *portView {
+	v := &portView{MaritimePort: p}
+
+	if p.Coordinates != nil {
+		v.Coordinates = &coordsView{point: *p.Coordinates, format: format}
+	}
+
+	return v
+}
+
+// newPortViews returns the views of ports with their coordinates encoded in format.
+func newPortViews(ports []*portsmanaging.MaritimePort, format coordsFormat) []*portView {
+	views := make([]*portView, 0, len(ports))
+
+	for _, p := range ports {
+		views = append(views, newPortView(p, format))
+	}
+
+	return views
+}
+
+// scoredPortView is a portsmanaging.ScoredPort as represented in responses.
+type scoredPortView struct {
+	*portsmanaging.ScoredPort
+	Port *portView `json:"port"`
+}
+
+// newScoredPortViews returns the views of scored ports with their coordinates encoded in format.
+func newScoredPortViews(ports []*portsmanaging.ScoredPort, format coordsFormat) []*scoredPortView {
+	views := make([]*scoredPortView, 0, len(ports))
+
+	for _, p := range ports {
+		views = append(views, &scoredPortView{ScoredPort: p, Port: newPortView(p.Port, format)})
+	}
+
+	return views
+}
+
+// portDistanceView is a portsmanaging.PortDistance as represented in responses.
+type portDistanceView struct {
+	*portsmanaging.PortDistance
+	Port *portView `json:"port"`
+}
+
+// newPortDistanceViews returns the views of port distances with their coordinates encoded in format.
+func newPortDistanceViews(ports []*portsmanaging.PortDistance, format coordsFormat) []*portDistanceView {
+	views := make([]*portDistanceView, 0, len(ports))
+
+	for _, p := range ports {
+		views = append(views, &portDistanceView{PortDistance: p, Port: newPortView(p.Port, format)})
+	}
+
+	return views
+}
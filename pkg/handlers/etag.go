@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// etag returns the strong entity tag of a port version.
+func etag(version uint64) string {
+	return strconv.Quote(strconv.FormatUint(version, 10))
+}
+
+// parsePrecondition maps the If-Match and If-None-Match headers of a write request to a
+// portsmanaging.Precondition. If-Match takes either '*' or a list of entity tags previously
+// returned as ETag, while If-None-Match only takes '*'. If-Match compares entity tags
+// strongly, so weak entity tags are accepted but never match.
+func parsePrecondition(header http.Header) (portsmanaging.Precondition, error) {
+	var cond portsmanaging.Precondition
+
+	if v := strings.TrimSpace(header.Get("If-Match")); v == "*" {
+		cond.MustExist = true
+	} else if v != "" {
+		for _, tag := range strings.Split(v, ",") {
+			tag = strings.TrimSpace(tag)
+			weak := strings.HasPrefix(tag, "W/")
+
+			version, err := parseETag(strings.TrimPrefix(tag, "W/"))
+			if err != nil {
+				return cond, newParamError(
+					"If-Match", fmt.Sprintf("header 'If-Match' must be '*' or a list of entity tags, got '%s'", v))
+			}
+
+			if weak {
+				// Port versions start at 1, so no port matches version 0.
+				version = 0
+			}
+
+			cond.IfMatch = append(cond.IfMatch, version)
+		}
+	}
+
+	switch v := strings.TrimSpace(header.Get("If-None-Match")); v {
+	case "":
+	case "*":
+		cond.MustNotExist = true
+	default:
+		return cond, newParamError("If-None-Match", fmt.Sprintf("header 'If-None-Match' must be '*', got '%s'", v))
+	}
+
+	return cond, nil
+}
+
+// parseETag returns the port version of a strong entity tag.
+func parseETag(tag string) (uint64, error) {
+	s, err := strconv.Unquote(tag)
+	if err != nil || !strings.HasPrefix(tag, `"`) {
+		return 0, strconv.ErrSyntax
+	}
+
+	return strconv.ParseUint(s, 10, 64)
+}
@@ -11,7 +11,7 @@ func InitializeHandlers(
 	router *mux.Router,
 	service PortsService,
 ) *mux.Router {
-	handler := NewPortsHandler(service)
+	handler := NewPortsHandler(service, config.CacheControl)
 
 	registerHTTPRoutes(config, router, handler)
 
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// parseListOptions maps the pagination and sorting query params to portsmanaging.ListOptions.
+func parseListOptions(query url.Values) (portsmanaging.ListOptions, error) {
+	var (
+		opts portsmanaging.ListOptions
+		err  error
+	)
+
+	if opts.Limit, err = intQueryParam(query, "limit"); err != nil {
+		return opts, err
+	}
+
+	if opts.Offset, err = intQueryParam(query, "offset"); err != nil {
+		return opts, err
+	}
+
+	opts.Cursor = query.Get("cursor")
+	opts.Filter = parsePortFilter(query)
+
+	sortBy := query.Get("sort")
+	if strings.HasPrefix(sortBy, "-") {
+		opts.Desc = true
+		sortBy = sortBy[1:]
+	}
+
+	opts.SortBy = portsmanaging.SortField(sortBy)
+
+	return opts, nil
+}
+
+// parsePortFilter maps the field filter query params to portsmanaging.PortFilter.
+// A value ending with '*' is matched as a case-insensitive prefix, otherwise exactly and case-sensitively.
+func parsePortFilter(query url.Values) portsmanaging.PortFilter {
+	var filter portsmanaging.PortFilter
+
+	for name, match := range map[string]*portsmanaging.FieldMatch{
+		"name":     &filter.Name,
+		"country":  &filter.Country,
+		"city":     &filter.City,
+		"province": &filter.Province,
+		"timezone": &filter.Timezone,
+		"region":   &filter.Region,
+		"unloc":    &filter.Unloc,
+		"code":     &filter.Code,
+		"alias":    &filter.Alias,
+	} {
+		v := query.Get(name)
+		if strings.HasSuffix(v, "*") {
+			match.Value = strings.TrimSuffix(v, "*")
+			match.Prefix = true
+		} else {
+			match.Value = v
+		}
+	}
+
+	return filter
+}
+
+// parseNearestQuery maps the nearest ports query params to portsmanaging.NearestQuery.
+func parseNearestQuery(query url.Values) (portsmanaging.NearestQuery, error) {
+	var (
+		q   portsmanaging.NearestQuery
+		err error
+	)
+
+	if q.Lat, err = requiredFloatQueryParam(query, "lat"); err != nil {
+		return q, err
+	}
+
+	if q.Lon, err = requiredFloatQueryParam(query, "lon"); err != nil {
+		return q, err
+	}
+
+	if q.K, err = intQueryParam(query, "k"); err != nil {
+		return q, err
+	}
+
+	if q.MaxKm, err = floatQueryParam(query, "max_km"); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// parseBoundingBox maps the bbox query param in the form minLon,minLat,maxLon,maxLat
+// to portsmanaging.BoundingBox.
+func parseBoundingBox(query url.Values) (portsmanaging.BoundingBox, error) {
+	v, err := floatListQueryParam(query, "bbox", 4)
+	if err != nil {
+		return portsmanaging.BoundingBox{}, err
+	}
+
+	return portsmanaging.BoundingBox{
+		MinLon: v[0],
+		MinLat: v[1],
+		MaxLon: v[2],
+		MaxLat: v[3],
+	}, nil
+}
+
+// parseRadiusQuery maps the center query param in the form lat,lon and
+// the radius_km query param to portsmanaging.RadiusQuery.
+func parseRadiusQuery(query url.Values) (portsmanaging.RadiusQuery, error) {
+	var q portsmanaging.RadiusQuery
+
+	center, err := floatListQueryParam(query, "center", 2)
+	if err != nil {
+		return q, err
+	}
+
+	q.Lat, q.Lon = center[0], center[1]
+
+	if q.RadiusKm, err = requiredFloatQueryParam(query, "radius_km"); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// parseSearchQuery maps the search query params to portsmanaging.SearchQuery.
+func parseSearchQuery(query url.Values) (portsmanaging.SearchQuery, error) {
+	var (
+		q   portsmanaging.SearchQuery
+		err error
+	)
+
+	if !query.Has("q") {
+		return q, newParamError("q", "required query param 'q' is missing")
+	}
+
+	q.Text = query.Get("q")
+
+	if q.Limit, err = intQueryParam(query, "limit"); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// parseSuggestQuery maps the suggest query params to portsmanaging.SuggestQuery.
+func parseSuggestQuery(query url.Values) (portsmanaging.SuggestQuery, error) {
+	var (
+		q   portsmanaging.SuggestQuery
+		err error
+	)
+
+	if !query.Has("prefix") {
+		return q, newParamError("prefix", "required query param 'prefix' is missing")
+	}
+
+	q.Prefix = query.Get("prefix")
+
+	if q.Limit, err = intQueryParam(query, "limit"); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+func intQueryParam(query url.Values, name string) (int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, newParamError(name, fmt.Sprintf("query param '%s' must be an integer, got '%s'", name, v))
+	}
+
+	return i, nil
+}
+
+func boolQueryParam(query url.Values, name string) (bool, error) {
+	v := query.Get(name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, newParamError(name, fmt.Sprintf("query param '%s' must be a boolean, got '%s'", name, v))
+	}
+
+	return b, nil
+}
+
+func floatQueryParam(query url.Values, name string) (float64, error) {
+	v := query.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, newParamError(name, fmt.Sprintf("query param '%s' must be a number, got '%s'", name, v))
+	}
+
+	return f, nil
+}
+
+func floatListQueryParam(query url.Values, name string, n int) ([]float64, error) {
+	v := query.Get(name)
+	parts := strings.Split(v, ",")
+	errList := newParamError(
+		name, fmt.Sprintf("query param '%s' must be a list of %d comma separated numbers, got '%s'", name, n, v))
+
+	if len(parts) != n {
+		return nil, errList
+	}
+
+	result := make([]float64, n)
+
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, errList
+		}
+
+		result[i] = f
+	}
+
+	return result, nil
+}
+
+func requiredFloatQueryParam(query url.Values, name string) (float64, error) {
+	if !query.Has(name) {
+		return 0, newParamError(name, fmt.Sprintf("required query param '%s' is missing", name))
+	}
+
+	return floatQueryParam(query, name)
+}
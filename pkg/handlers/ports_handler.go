@@ -1,63 +1,391 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"mime"
 	"net/http"
 
-
 	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
 
 	"github.com/gorilla/mux"
 	pkgErrors "github.com/pkg/errors"
 )
 
+const (
+	mediaTypeMergePatch = "application/merge-patch+json"
+	mediaTypeJSONPatch  = "application/json-patch+json"
+)
+
 // PortsService is a port interface for operations on portsmanaging.MaritimePort.
 type PortsService interface {
-	GetAllPorts() ([]*portsmanaging.MaritimePort, error)
-	GetPortByID(ID string) (*portsmanaging.MaritimePort, error)
-	CreateOrUpdatePort(p *portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, bool, error)
+	ListPorts(ctx context.Context, opts portsmanaging.ListOptions) (*portsmanaging.PortsPage, error)
+	NearestPorts(ctx context.Context, query portsmanaging.NearestQuery) ([]*portsmanaging.PortDistance, error)
+	PortsWithinRadius(ctx context.Context, query portsmanaging.RadiusQuery) ([]*portsmanaging.PortDistance, error)
+	PortsWithinBox(ctx context.Context, box portsmanaging.BoundingBox) ([]*portsmanaging.MaritimePort, error)
+	SearchPorts(ctx context.Context, query portsmanaging.SearchQuery) ([]*portsmanaging.ScoredPort, error)
+	SuggestPorts(ctx context.Context, query portsmanaging.SuggestQuery) ([]*portsmanaging.PortSuggestion, error)
+	GetPortByID(ctx context.Context, ID string) (*portsmanaging.MaritimePort, error)
+	Revision(ctx context.Context) (portsmanaging.Revision, error)
+	CreateOrUpdatePort(
+		ctx context.Context, p *portsmanaging.MaritimePort, cond portsmanaging.Precondition,
+	) (*portsmanaging.MaritimePort, bool, error)
+	PatchPort(
+		ctx context.Context, ID string, patch []byte, format portsmanaging.PatchFormat, cond portsmanaging.Precondition,
+	) (*portsmanaging.MaritimePort, error)
+	UpsertPorts(
+		ctx context.Context, items []*portsmanaging.BatchItem, atomic bool,
+	) ([]*portsmanaging.UpsertResult, error)
+	ImportPorts(ctx context.Context, r io.Reader, format portsmanaging.DataFormat) (int, error)
+	ExportPorts(ctx context.Context, w io.Writer, format portsmanaging.DataFormat) (int, error)
+	DeletePort(ctx context.Context, ID string, cond portsmanaging.Precondition) error
 }
 
 // PortsHandler represents an HTTP handler for Ethereum block operations.
 type PortsHandler struct {
-	Service PortsService
+	Service      PortsService
+	CacheControl string
 }
 
-// NewPortsHandler initializes a new instance of PortsHandler.
-func NewPortsHandler(service PortsService) *PortsHandler {
+// NewPortsHandler initializes a new instance of PortsHandler. The cacheControl directives
+// are sent with cacheable responses, unless empty.
+func NewPortsHandler(service PortsService, cacheControl string) *PortsHandler {
 	return &PortsHandler{
-		Service: service,
+		Service:      service,
+		CacheControl: cacheControl,
 	}
 }
 
 // GetAllPorts godoc
 // @Summary Get all ports stored in the system.
-// @Description Get all ports stored in the system.
+// @Description Get all ports stored in the system in a stable order, optionally paginated.
+// @Description Pages are addressed either by `offset` or by the `next_cursor` of the previous page.
+// @Description Field filters match exact values case-sensitively, or prefixes case-insensitively when their
+// @Description value ends with `*`.
+// @Description Responses can be revalidated with `If-None-Match` or `If-Modified-Since`, which yield
+// @Description 304 Not Modified as long as no port has changed.
 // @Tags ports
 // @Accept  json
 // @Produce  json
+// @Produce  application/problem+json
+// @Param limit query int false "Maximum number of ports in the page (default 100, capped at 1000)"
+// @Param offset query int false "Number of ports to skip"
+// @Param cursor query string false "Cursor returned as next_cursor with the previous page"
+// @Param sort query string false "Sort field (id, name, country, city), prefixed with '-' for descending order"
+// @Param name query string false "Name filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param country query string false "Country filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param city query string false "City filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param province query string false "Province filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param timezone query string false "Timezone filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param region query string false "Region filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param unloc query string false "UN/LOCODE filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param code query string false "Code filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param alias query string false "Alias filter (case-sensitive), or a case-insensitive prefix ending with '*'"
+// @Param coords query string false "Coordinates format: array ([lon, lat], default) or object ({lat, lon})"
+// @Param If-None-Match header string false "Entity tags of cached responses"
+// @Param If-Modified-Since header string false "Last-Modified time of a cached response"
+// @Header 200,304 {string} ETag "Entity tag of the revision of all ports"
+// @Header 200,304 {string} Last-Modified "Time of the last modification of any port"
+// @Header 200,304 {string} Cache-Control "Caching policy"
+// @Success 304 "Not modified"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 422 {object} handlers.Problem "Validation failed"
+// @Failure 500 {object} handlers.Problem "Internal error"
 // @Router /api/v1/ports [get]
 func (h *PortsHandler) GetAllPorts() http.HandlerFunc {
 	type response struct {
-		Result []*portsmanaging.MaritimePort `json:"result"`
+		Result     []*portView `json:"result"`
+		Total      int         `json:"total"`
+		NextCursor string      `json:"next_cursor,omitempty"`
 	}
 
 	return func(rw http.ResponseWriter, r *http.Request) {
-		ports, err := h.Service.GetAllPorts()
+		coords, err := parseCoordsFormat(r.URL.Query())
 		if err != nil {
-			badRequestError(
-				rw,
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		opts, err := parseListOptions(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		// Invalid options are reported even if the client already holds a current copy.
+		if err = opts.Validate(); err != nil {
+			handleError(
+				rw, r,
+				pkgErrors.Wrapf(err, "could not get all ports"),
+			)
+
+			return
+		}
+
+		// The revision is read before the ports, so that a concurrent modification
+		// can only make the response look older than it is and never newer.
+		rev, err := h.Service.Revision(r.Context())
+		if err != nil {
+			handleError(rw, r, err)
+
+			return
+		}
+
+		v := validators{etag: revisionETag(rev), lastModified: rev.ModifiedAt}
+		if h.handleNotModified(rw, r, v) {
+			return
+		}
+
+		page, err := h.Service.ListPorts(r.Context(), opts)
+		if err != nil {
+			handleError(
+				rw, r,
 				pkgErrors.Wrapf(err, "could not get all ports"),
 			)
 
 			return
 		}
 
+		h.writeValidators(rw, v)
 		handleResponse(rw, response{
-			Result: ports,
+			Result:     newPortViews(page.Ports, coords),
+			Total:      page.Total,
+			NextCursor: page.NextCursor,
+		})
+	}
+}
+
+// GetNearestPorts godoc
+// @Summary Get the ports nearest to a location.
+// @Description Get the k ports nearest to a location ordered by ascending great-circle distance in kilometers.
+// @Tags ports
+// @Accept  json
+// @Produce  json
+// @Produce  application/problem+json
+// @Param lat query number true "Latitude in degrees"
+// @Param lon query number true "Longitude in degrees"
+// @Param k query int false "Number of ports to return (default 10, max 100)"
+// @Param max_km query number false "Maximum distance in kilometers"
+// @Param coords query string false "Coordinates format: array ([lon, lat], default) or object ({lat, lon})"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 422 {object} handlers.Problem "Validation failed"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports/nearest [get]
+func (h *PortsHandler) GetNearestPorts() http.HandlerFunc {
+	type response struct {
+		Result []*portDistanceView `json:"result"`
+	}
+
+	return func(rw http.ResponseWriter, r *http.Request) {
+		coords, err := parseCoordsFormat(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		query, err := parseNearestQuery(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		ports, err := h.Service.NearestPorts(r.Context(), query)
+		if err != nil {
+			handleError(
+				rw, r,
+				pkgErrors.Wrap(err, "could not get nearest ports"),
+			)
+
+			return
+		}
+
+		handleResponse(rw, response{
+			Result: newPortDistanceViews(ports, coords),
+		})
+	}
+}
+
+// GetPortsWithin godoc
+// @Summary Get the ports inside a bounding box or a circle.
+// @Description Get the ports inside either a bounding box given by `bbox` or a circle given by `center`
+// @Description and `radius_km`. A bounding box with a minimum longitude greater than its maximum longitude
+// @Description crosses the antimeridian. Ports in a bounding box are ordered by ID and ports in a circle
+// @Description are returned together with their great-circle distance to the center in ascending order.
+// @Tags ports
+// @Accept  json
+// @Produce  json
+// @Produce  application/problem+json
+// @Param bbox query string false "Bounding box as minLon,minLat,maxLon,maxLat"
+// @Param center query string false "Circle center as lat,lon"
+// @Param radius_km query number false "Circle radius in kilometers"
+// @Param coords query string false "Coordinates format: array ([lon, lat], default) or object ({lat, lon})"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 422 {object} handlers.Problem "Validation failed"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports/within [get]
+func (h *PortsHandler) GetPortsWithin() http.HandlerFunc {
+	type boxResponse struct {
+		Result []*portView `json:"result"`
+	}
+
+	type radiusResponse struct {
+		Result []*portDistanceView `json:"result"`
+	}
+
+	return func(rw http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		coords, errCoords := parseCoordsFormat(query)
+		if errCoords != nil {
+			badRequestError(rw, r, errCoords)
+
+			return
+		}
+
+		switch {
+		case query.Has("bbox") && query.Has("center"):
+			badRequestError(rw, r, errors.New("query params 'bbox' and 'center' are mutually exclusive"))
+		case query.Has("bbox"):
+			box, err := parseBoundingBox(query)
+			if err != nil {
+				badRequestError(rw, r, err)
+
+				return
+			}
+
+			ports, err := h.Service.PortsWithinBox(r.Context(), box)
+			if err != nil {
+				handleError(rw, r, pkgErrors.Wrap(err, "could not get ports within bounding box"))
+
+				return
+			}
+
+			handleResponse(rw, boxResponse{
+				Result: newPortViews(ports, coords),
+			})
+		case query.Has("center"):
+			radiusQuery, err := parseRadiusQuery(query)
+			if err != nil {
+				badRequestError(rw, r, err)
+
+				return
+			}
+
+			ports, err := h.Service.PortsWithinRadius(r.Context(), radiusQuery)
+			if err != nil {
+				handleError(rw, r, pkgErrors.Wrap(err, "could not get ports within radius"))
+
+				return
+			}
+
+			handleResponse(rw, radiusResponse{
+				Result: newPortDistanceViews(ports, coords),
+			})
+		default:
+			badRequestError(rw, r, errors.New("either query param 'bbox' or 'center' is required"))
+		}
+	}
+}
+
+// SearchPorts godoc
+// @Summary Search ports by free text.
+// @Description Search ports by name, city, province, alias and UN/LOCODE. Terms are matched regardless
+// @Description of case and diacritics, as prefixes and with tolerance for typos. Ports are returned
+// @Description together with their relevance score in descending order.
+// @Tags ports
+// @Accept  json
+// @Produce  json
+// @Produce  application/problem+json
+// @Param q query string true "Search text of at most 200 characters and 10 terms"
+// @Param limit query int false "Maximum number of ports to return (default 20, max 100)"
+// @Param coords query string false "Coordinates format: array ([lon, lat], default) or object ({lat, lon})"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 422 {object} handlers.Problem "Validation failed"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports/search [get]
+func (h *PortsHandler) SearchPorts() http.HandlerFunc {
+	type response struct {
+		Result []*scoredPortView `json:"result"`
+	}
+
+	return func(rw http.ResponseWriter, r *http.Request) {
+		coords, err := parseCoordsFormat(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		query, err := parseSearchQuery(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		ports, err := h.Service.SearchPorts(r.Context(), query)
+		if err != nil {
+			handleError(
+				rw, r,
+				pkgErrors.Wrap(err, "could not search ports"),
+			)
+
+			return
+		}
+
+		handleResponse(rw, response{
+			Result: newScoredPortViews(ports, coords),
+		})
+	}
+}
+
+// SuggestPorts godoc
+// @Summary Suggest ports while typing.
+// @Description Suggest ports with a name, alias or UN/LOCODE having a word starting with a prefix,
+// @Description matched regardless of case and diacritics. Suggestions are ordered by the matching value.
+// @Tags ports
+// @Accept  json
+// @Produce  json
+// @Produce  application/problem+json
+// @Param prefix query string true "Prefix typed so far"
+// @Param limit query int false "Maximum number of suggestions to return (default 10, max 50)"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 422 {object} handlers.Problem "Validation failed"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports/suggest [get]
+func (h *PortsHandler) SuggestPorts() http.HandlerFunc {
+	type response struct {
+		Result []*portsmanaging.PortSuggestion `json:"result"`
+	}
+
+	return func(rw http.ResponseWriter, r *http.Request) {
+		query, err := parseSuggestQuery(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		suggestions, err := h.Service.SuggestPorts(r.Context(), query)
+		if err != nil {
+			handleError(
+				rw, r,
+				pkgErrors.Wrap(err, "could not suggest ports"),
+			)
+
+			return
+		}
+
+		handleResponse(rw, response{
+			Result: suggestions,
 		})
 	}
 }
@@ -65,50 +393,73 @@ func (h *PortsHandler) GetAllPorts() http.HandlerFunc {
 // GetPort godoc
 // @Summary Get an existing port by ID.
 // @Description Get an existing port by ID.
+// @Description Responses can be revalidated with `If-None-Match` or `If-Modified-Since`, which yield
+// @Description 304 Not Modified as long as the port has not changed.
 // @Tags ports
 // @Accept  json
 // @Produce  json
+// @Produce  application/problem+json
 // @Param id path string true "MaritimePort ID"
+// @Param coords query string false "Coordinates format: array ([lon, lat], default) or object ({lat, lon})"
+// @Param If-None-Match header string false "Entity tags of cached port versions"
+// @Param If-Modified-Since header string false "Last-Modified time of a cached response"
+// @Header 200,304 {string} ETag "Entity tag of the port version"
+// @Header 200,304 {string} Last-Modified "Time of the last modification of any port"
+// @Header 200,304 {string} Cache-Control "Caching policy"
+// @Success 304 "Not modified"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 404 {object} handlers.Problem "Port not found"
+// @Failure 500 {object} handlers.Problem "Internal error"
 // @Router /api/v1/ports/{id} [get]
 func (h *PortsHandler) GetPort() http.HandlerFunc {
 	type response struct {
-		Result *portsmanaging.MaritimePort `json:"result"`
+		Result *portView `json:"result"`
 	}
 
 	return func(rw http.ResponseWriter, r *http.Request) {
+		coords, err := parseCoordsFormat(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
 		vars := mux.Vars(r)
 
 		id, ok := vars["id"]
 		if !ok {
 			badRequestError(
-				rw,
-				errors.New("required path param 'id' is missing"),
+				rw, r,
+				newParamError("id", "required path param 'id' is missing"),
 			)
 
 			return
 		}
 
-		p, err := h.Service.GetPortByID(id)
+		rev, err := h.Service.Revision(r.Context())
 		if err != nil {
-			badRequestError(
-				rw,
-				pkgErrors.Wrapf(err, "error getting port entry with ID '%s'", id),
-			)
+			handleError(rw, r, err)
 
 			return
 		}
 
-		if p == nil {
-			notFoundError(
-				rw,
-				fmt.Errorf("port entry with ID '%s' not found", id),
-			)
+		p, err := h.Service.GetPortByID(r.Context(), id)
+		if err != nil {
+			handleError(rw, r, err)
+
+			return
+		}
 
+		// Ports are not timestamped individually, so the port is as recent as the
+		// revision of all ports.
+		v := validators{etag: etag(p.Version), lastModified: rev.ModifiedAt}
+		if h.handleNotModified(rw, r, v) {
 			return
 		}
 
+		h.writeValidators(rw, v)
 		handleResponse(rw, response{
-			Result: p,
+			Result: newPortView(p, coords),
 		})
 	}
 }
@@ -116,10 +467,22 @@ func (h *PortsHandler) GetPort() http.HandlerFunc {
 // CreateOrUpdatePort godoc
 // @Summary Create a new port or update an existing one.
 // @Description Create a new port or update an existing one.
+// @Description The `id` and all `unlocs` must be valid UN/LOCODEs and `name` and `country` are required.
+// @Description The `coordinates`, when known, are a [longitude, latitude] pair and the `timezone`,
+// @Description when known, is an IANA time zone name. All violations are reported at once.
+// @Description Concurrent modifications are detected by passing the ETag of the port as `If-Match`.
 // @Tags ports
 // @Accept  json
 // @Produce  json
+// @Produce  application/problem+json
 // @Param request body portsmanaging.MaritimePort true "MaritimePort Entry"
+// @Param If-Match header string false "Entity tags of the expected port versions, or * for any existing port"
+// @Param If-None-Match header string false "* to only create a port which does not exist yet"
+// @Header 200 {string} ETag "Entity tag of the port version"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 412 {object} handlers.Problem "Port version does not match"
+// @Failure 422 {object} handlers.Problem "Validation failed"
+// @Failure 500 {object} handlers.Problem "Internal error"
 // @Router /api/v1/ports [post]
 func (h *PortsHandler) CreateOrUpdatePort() http.HandlerFunc {
 	type response struct {
@@ -129,6 +492,13 @@ func (h *PortsHandler) CreateOrUpdatePort() http.HandlerFunc {
 	}
 
 	return func(rw http.ResponseWriter, r *http.Request) {
+		cond, err := parsePrecondition(r.Header)
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
 		var reqBody portsmanaging.MaritimePort
 
 		reqBytes, errReqBytes := io.ReadAll(r.Body)
@@ -137,7 +507,7 @@ func (h *PortsHandler) CreateOrUpdatePort() http.HandlerFunc {
 		errReq := errors.Join(errReqBytes, errReqUnmarshal)
 		if errReq != nil {
 			badRequestError(
-				rw,
+				rw, r,
 				pkgErrors.Wrap(errReq, "could not unmarshal request params"),
 			)
 
@@ -146,23 +516,24 @@ func (h *PortsHandler) CreateOrUpdatePort() http.HandlerFunc {
 
 		if reqBody.ID == "" {
 			badRequestError(
-				rw,
-				errors.New("required body param 'id' is missing"),
+				rw, r,
+				newParamError("id", "required body param 'id' is missing"),
 			)
 
 			return
 		}
 
-		p, exists, err := h.Service.CreateOrUpdatePort(&reqBody)
+		p, exists, err := h.Service.CreateOrUpdatePort(r.Context(), &reqBody, cond)
 		if err != nil {
-			badRequestError(
-				rw,
-				pkgErrors.Wrap(errReq, "could not create/update port"),
+			handleError(
+				rw, r,
+				pkgErrors.Wrap(err, "could not create/update port"),
 			)
 
 			return
 		}
 
+		rw.Header().Set("ETag", etag(p.Version))
 		handleResponse(rw, response{
 			Success: true,
 			Exists:  exists,
@@ -171,44 +542,323 @@ func (h *PortsHandler) CreateOrUpdatePort() http.HandlerFunc {
 	}
 }
 
-func handleResponse(rw http.ResponseWriter, resp any) {
-	jsonResp, errRespMarshal := json.Marshal(resp)
-	_, errRespWrite := rw.Write(jsonResp)
+// UpsertPorts godoc
+// @Summary Create or update a batch of ports.
+// @Description Create or update a batch of ports given either as an array of ports or as an object
+// @Description of ports keyed by their IDs, like the ports fixtures. Ports are validated like single
+// @Description ports and applied in order, each of them independently unless `atomic` is set, in which
+// @Description case either all of them are applied or, responding with 422, none of them.
+// @Description The outcome of every port is listed in the order of the request, ports which cannot be
+// @Description decoded failing like invalid ones. The request body must not be larger than 8 MiB.
+// @Tags ports
+// @Accept  json
+// @Produce  json
+// @Produce  application/problem+json
+// @Param request body []portsmanaging.MaritimePort true "Batch of MaritimePort entries"
+// @Param atomic query bool false "Apply all ports or none of them"
+// @Success 200 {object} handlers.BatchResponse
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 413 {object} handlers.Problem "Request body too large"
+// @Failure 422 {object} handlers.BatchResponse "Atomic batch not applied"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports:batch [post]
+func (h *PortsHandler) UpsertPorts() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		atomic, err := boolQueryParam(r.URL.Query(), "atomic")
+		if err != nil {
+			badRequestError(rw, r, err)
 
-	errResp := errors.Join(errRespMarshal, errRespWrite)
-	if errResp != nil {
-		http.Error(rw, errResp.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		items, err := decodeBatch(http.MaxBytesReader(rw, r.Body, maxBatchBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeProblem(rw, r, http.StatusRequestEntityTooLarge, err)
+
+				return
+			}
+
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		results, err := h.Service.UpsertPorts(r.Context(), items, atomic)
+		if err != nil {
+			handleError(
+				rw, r,
+				pkgErrors.Wrap(err, "could not create/update ports"),
+			)
+
+			return
+		}
+
+		resp := newBatchResponse(results)
+		if atomic && resp.Failed > 0 {
+			rw.Header().Set("Content-Type", mediaTypeJSON)
+			rw.WriteHeader(http.StatusUnprocessableEntity)
+		}
+
+		handleResponse(rw, resp)
 	}
 }
 
-func badRequestError(rw http.ResponseWriter, err error) {
-	errBytes, err := json.Marshal(struct {
-		Status int    `json:"status"`
-		Error  string `json:"error"`
-	}{
-		Status: http.StatusBadRequest,
-		Error:  err.Error(),
-	})
+// ImportPorts godoc
+// @Summary Import ports.
+// @Description Import ports given either as a JSON object of ports keyed by their IDs, like the ports
+// @Description fixtures, or as newline-delimited JSON with a single port including its ID per line.
+// @Description The request body is streamed into the store port by port, creating new ports and
+// @Description updating existing ones. Importing stops at the first invalid port, keeping the ports
+// @Description imported so far.
+// @Tags ports
+// @Accept  json
+// @Accept  application/x-ndjson
+// @Produce  json
+// @Produce  application/problem+json
+// @Param request body object true "Ports data"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 415 {object} handlers.Problem "Unsupported content type"
+// @Failure 422 {object} handlers.Problem "Malformed data or invalid port"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports/import [post]
+func (h *PortsHandler) ImportPorts() http.HandlerFunc {
+	type response struct {
+		Success  bool `json:"success"`
+		Imported int  `json:"imported"`
+	}
 
-	if err == nil {
-		http.Error(rw, string(errBytes), http.StatusBadRequest)
-	} else {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	return func(rw http.ResponseWriter, r *http.Request) {
+		format, err := importFormat(r.Header.Get("Content-Type"))
+		if err != nil {
+			unsupportedMediaTypeError(rw, r, err)
+
+			return
+		}
+
+		imported, err := h.Service.ImportPorts(r.Context(), r.Body, format)
+		if err != nil {
+			handleError(
+				rw, r,
+				pkgErrors.Wrapf(err, "could not import ports after importing %d ports", imported),
+			)
+
+			return
+		}
+
+		handleResponse(rw, response{
+			Success:  true,
+			Imported: imported,
+		})
 	}
 }
 
-func notFoundError(rw http.ResponseWriter, err error) {
-	errBytes, err := json.Marshal(struct {
-		Status int    `json:"status"`
-		Error  string `json:"error"`
-	}{
-		Status: http.StatusNotFound,
-		Error:  err.Error(),
-	})
+// ExportPorts godoc
+// @Summary Export all ports.
+// @Description Export all ports ordered by ID either as a JSON object of ports keyed by their IDs,
+// @Description in the same shape as the ports fixtures, or as newline-delimited JSON with a single
+// @Description port including its ID per line. The response is streamed.
+// @Tags ports
+// @Produce  json
+// @Produce  application/x-ndjson
+// @Produce  application/problem+json
+// @Param format query string false "Data format: json (default) or ndjson"
+// @Success 200 {object} object "Ports data"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports/export [get]
+func (h *PortsHandler) ExportPorts() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		format, mediaType, fileName, err := exportFormat(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		rw.Header().Set("Content-Type", mediaType)
+		rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+		cw := &countingWriter{w: rw}
+
+		_, err = h.Service.ExportPorts(r.Context(), cw, format)
+		if err == nil {
+			return
+		}
+
+		// Once streaming has started, the response can only be cut short.
+		if cw.n > 0 {
+			log.Printf("[ExportPorts] export aborted: %v\n", err)
+
+			return
+		}
 
-	if err == nil {
-		http.Error(rw, string(errBytes), http.StatusNotFound)
-	} else {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		rw.Header().Del("Content-Disposition")
+		handleError(rw, r, pkgErrors.Wrap(err, "could not export ports"))
+	}
+}
+
+// PatchPort godoc
+// @Summary Partially update an existing port by ID.
+// @Description Partially update an existing port by ID with either a JSON Merge Patch (RFC 7396)
+// @Description or a JSON Patch (RFC 6902) document, selected by the request content type.
+// @Description Removing a member (`null` in a merge patch, `remove` in a JSON patch) clears it:
+// @Description `alias`, `regions` and `unlocs` become empty lists, `coordinates` becomes null
+// @Description and string members like `code` become empty. The `id` member cannot be changed.
+// @Tags ports
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
+// @Produce  json
+// @Produce  application/problem+json
+// @Param id path string true "MaritimePort ID"
+// @Param request body object true "Patch document"
+// @Param coords query string false "Coordinates format: array ([lon, lat], default) or object ({lat, lon})"
+// @Param If-Match header string false "Entity tags of the expected port versions, or * for any existing port"
+// @Header 200 {string} ETag "Entity tag of the port version"
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 404 {object} handlers.Problem "Port not found"
+// @Failure 409 {object} handlers.Problem "Patch conflicts with the port"
+// @Failure 412 {object} handlers.Problem "Port version does not match"
+// @Failure 415 {object} handlers.Problem "Unsupported content type"
+// @Failure 422 {object} handlers.Problem "Validation failed"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports/{id} [patch]
+func (h *PortsHandler) PatchPort() http.HandlerFunc {
+	type response struct {
+		Result *portView `json:"result"`
+	}
+
+	return func(rw http.ResponseWriter, r *http.Request) {
+		coords, err := parseCoordsFormat(r.URL.Query())
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		vars := mux.Vars(r)
+
+		id, ok := vars["id"]
+		if !ok {
+			badRequestError(
+				rw, r,
+				newParamError("id", "required path param 'id' is missing"),
+			)
+
+			return
+		}
+
+		format, err := patchFormat(r.Header.Get("Content-Type"))
+		if err != nil {
+			unsupportedMediaTypeError(rw, r, err)
+
+			return
+		}
+
+		cond, err := parsePrecondition(r.Header)
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		patch, err := io.ReadAll(r.Body)
+		if err != nil {
+			badRequestError(
+				rw, r,
+				pkgErrors.Wrap(err, "could not read request body"),
+			)
+
+			return
+		}
+
+		p, err := h.Service.PatchPort(r.Context(), id, patch, format, cond)
+		if err != nil {
+			handleError(rw, r, err)
+
+			return
+		}
+
+		rw.Header().Set("ETag", etag(p.Version))
+
+		handleResponse(rw, response{
+			Result: newPortView(p, coords),
+		})
+	}
+}
+
+// DeletePort godoc
+// @Summary Delete an existing port by ID.
+// @Description Delete an existing port by ID.
+// @Tags ports
+// @Accept  json
+// @Produce  json
+// @Produce  application/problem+json
+// @Param id path string true "MaritimePort ID"
+// @Param If-Match header string false "Entity tags of the expected port versions, or * for any existing port"
+// @Success 204
+// @Failure 400 {object} handlers.Problem "Malformed request"
+// @Failure 404 {object} handlers.Problem "Port not found"
+// @Failure 412 {object} handlers.Problem "Port version does not match"
+// @Failure 500 {object} handlers.Problem "Internal error"
+// @Router /api/v1/ports/{id} [delete]
+func (h *PortsHandler) DeletePort() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		id, ok := vars["id"]
+		if !ok {
+			badRequestError(
+				rw, r,
+				newParamError("id", "required path param 'id' is missing"),
+			)
+
+			return
+		}
+
+		cond, err := parsePrecondition(r.Header)
+		if err != nil {
+			badRequestError(rw, r, err)
+
+			return
+		}
+
+		if err = h.Service.DeletePort(r.Context(), id, cond); err != nil {
+			handleError(rw, r, err)
+
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func patchFormat(contentType string) (portsmanaging.PatchFormat, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, fmt.Errorf("invalid content type '%s'", contentType)
+	}
+
+	switch mediaType {
+	case mediaTypeMergePatch:
+		return portsmanaging.MergePatch, nil
+	case mediaTypeJSONPatch:
+		return portsmanaging.JSONPatch, nil
+	default:
+		return 0, fmt.Errorf(
+			"unsupported content type '%s', expected '%s' or '%s'",
+			mediaType, mediaTypeMergePatch, mediaTypeJSONPatch,
+		)
+	}
+}
+
+func handleResponse(rw http.ResponseWriter, resp any) {
+	jsonResp, errRespMarshal := json.Marshal(resp)
+	_, errRespWrite := rw.Write(jsonResp)
+
+	errResp := errors.Join(errRespMarshal, errRespWrite)
+	if errResp != nil {
+		http.Error(rw, errResp.Error(), http.StatusInternalServerError)
 	}
 }
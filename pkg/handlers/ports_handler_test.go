@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kinbiko/jsonassert"
@@ -29,11 +31,13 @@ type tests struct {
 	httpMethod               string
 	httpEndpoint             string
 	httpPathParams           map[string]string
+	httpHeaders              map[string]string
 	httpRequestBody          string
 	handlerFunc              func(portsHandler *handlers.PortsHandler) http.HandlerFunc
 	expectedResponse         string
 	expectedResponseFileName string
 	expectedResponseCode     int
+	expectedResponseHeaders  map[string]string
 }
 
 func TestPortsHandlerCorrectResponses(t *testing.T) {
@@ -49,14 +53,334 @@ func TestPortsHandlerCorrectResponses(t *testing.T) {
 			},
 			expectedResponseCode:     http.StatusOK,
 			expectedResponseFileName: "get_all_ports_expected_response",
+			expectedResponseHeaders: map[string]string{
+				"Cache-Control": "no-cache",
+			},
+		},
+		{
+			testCaseName: "should return the first page of ports ordered by ID",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetAllPorts + "?limit=1",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetAllPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					  "id":"AEAJM",
+					  "name":"Ajman",
+					  "city":"Ajman",
+					  "country":"United Arab Emirates",
+					  "alias":[],
+					  "regions":[],
+					  "coordinates":[
+						 55.5136433,
+						 25.4052165
+					  ],
+					  "province":"Ajman",
+					  "timezone":"Asia/Dubai",
+					  "unlocs":[
+						 "AEAJM"
+					  ],
+					  "code":"52000"
+				  }
+			   ],
+			   "total":3,
+			   "next_cursor":"eyJzIjoiaWQiLCJkIjpmYWxzZSwiayI6IkFFQUpNIiwiaWQiOiJBRUFKTSJ9"
+			}`,
+		},
+		{
+			testCaseName: "should return the page of ports following a cursor",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetAllPorts +
+				"?limit=1&cursor=eyJzIjoiaWQiLCJkIjpmYWxzZSwiayI6IkFFQVVIIiwiaWQiOiJBRUFVSCJ9",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetAllPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					  "id":"AEDXB",
+					  "name":"Dubai",
+					  "city":"Dubai",
+					  "country":"United Arab Emirates",
+					  "alias":[],
+					  "regions":[],
+					  "coordinates":[
+						 55.27,
+						 25.25
+					  ],
+					  "province":"Dubayy [Dubai]",
+					  "timezone":"Asia/Dubai",
+					  "unlocs":[
+						 "AEDXB"
+					  ],
+					  "code":"52005"
+				  }
+			   ],
+			   "total":3
+			}`,
+		},
+		{
+			testCaseName: "should return the ports matching a case-insensitive prefix filter",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetAllPorts + "?province=dubAYY*&timezone=Asia/Dubai",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetAllPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					  "id":"AEDXB",
+					  "name":"Dubai",
+					  "city":"Dubai",
+					  "country":"United Arab Emirates",
+					  "alias":[],
+					  "regions":[],
+					  "coordinates":[
+						 55.27,
+						 25.25
+					  ],
+					  "province":"Dubayy [Dubai]",
+					  "timezone":"Asia/Dubai",
+					  "unlocs":[
+						 "AEDXB"
+					  ],
+					  "code":"52005"
+				  }
+			   ],
+			   "total":1
+			}`,
+		},
+		{
+			testCaseName: "should return a page of ports ordered by name in descending order",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetAllPorts + "?limit=1&sort=-name",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetAllPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					  "id":"AEDXB",
+					  "name":"Dubai",
+					  "city":"Dubai",
+					  "country":"United Arab Emirates",
+					  "alias":[],
+					  "regions":[],
+					  "coordinates":[
+						 55.27,
+						 25.25
+					  ],
+					  "province":"Dubayy [Dubai]",
+					  "timezone":"Asia/Dubai",
+					  "unlocs":[
+						 "AEDXB"
+					  ],
+					  "code":"52005"
+				  }
+			   ],
+			   "total":3,
+			   "next_cursor":"eyJzIjoibmFtZSIsImQiOnRydWUsImsiOiJkdWJhaSIsImlkIjoiQUVEWEIifQ"
+			}`,
+		},
+		{
+			testCaseName: "should return the nearest ports within a maximum distance",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetNearestPorts + "?lat=25.25&lon=55.27&k=2&max_km=10",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetNearestPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					 "port":{
+						"id":"AEDXB",
+						"name":"Dubai",
+						"city":"Dubai",
+						"country":"United Arab Emirates",
+						"alias":[],
+						"regions":[],
+						"coordinates":[
+						   55.27,
+						   25.25
+						],
+						"province":"Dubayy [Dubai]",
+						"timezone":"Asia/Dubai",
+						"unlocs":[
+						   "AEDXB"
+						],
+						"code":"52005"
+					 },
+					 "distance_km":0
+				  }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return the ports inside a bounding box",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetPortsWithin + "?bbox=55,25,56,26",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetPortsWithin()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					 "id":"AEAJM",
+					 "name":"Ajman",
+					 "city":"Ajman",
+					 "country":"United Arab Emirates",
+					 "alias":[],
+					 "regions":[],
+					 "coordinates":[
+						55.5136433,
+						25.4052165
+					 ],
+					 "province":"Ajman",
+					 "timezone":"Asia/Dubai",
+					 "unlocs":[
+						"AEAJM"
+					 ],
+					 "code":"52000"
+				  },
+				  {
+					 "id":"AEDXB",
+					 "name":"Dubai",
+					 "city":"Dubai",
+					 "country":"United Arab Emirates",
+					 "alias":[],
+					 "regions":[],
+					 "coordinates":[
+						55.27,
+						25.25
+					 ],
+					 "province":"Dubayy [Dubai]",
+					 "timezone":"Asia/Dubai",
+					 "unlocs":[
+						"AEDXB"
+					 ],
+					 "code":"52005"
+				  }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return the ports matching a search regardless of diacritics",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointSearchPorts + "?q=abu+zaby",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.SearchPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					 "port":{
+						"id":"AEAUH",
+						"name":"Abu Dhabi",
+						"city":"Abu Dhabi",
+						"country":"United Arab Emirates",
+						"alias":[],
+						"regions":[],
+						"coordinates":[
+						   54.37,
+						   24.47
+						],
+						"province":"Abu Z¸aby [Abu Dhabi]",
+						"timezone":"Asia/Dubai",
+						"unlocs":[
+						   "AEAUH"
+						],
+						"code":"52001"
+					 },
+					 "score":4
+				  }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return searched ports with coordinates as objects",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointSearchPorts + "?q=abu+zaby&coords=object",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.SearchPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					 "port":{
+						"id":"AEAUH",
+						"name":"Abu Dhabi",
+						"city":"Abu Dhabi",
+						"country":"United Arab Emirates",
+						"alias":[],
+						"regions":[],
+						"coordinates":{
+						   "lat":24.47,
+						   "lon":54.37
+						},
+						"province":"Abu Z¸aby [Abu Dhabi]",
+						"timezone":"Asia/Dubai",
+						"unlocs":[
+						   "AEAUH"
+						],
+						"code":"52001"
+					 },
+					 "score":4
+				  }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return compact suggestions for a prefix",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointSuggestPorts + "?prefix=a&limit=2",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.SuggestPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					 "id":"AEAUH",
+					 "name":"Abu Dhabi",
+					 "country":"United Arab Emirates"
+				  },
+				  {
+					 "id":"AEAJM",
+					 "name":"Ajman",
+					 "country":"United Arab Emirates"
+				  }
+			   ]
+			}`,
 		},
 		{
 			testCaseName: "should return a correct response for creating a new port",
 			httpMethod:   "POST",
 			httpEndpoint: handlers.EndpointCreateOrUpdatePort,
+			httpHeaders: map[string]string{
+				"If-None-Match": "*",
+			},
 			httpRequestBody: `
 			{
-				"id": "NEWPORT",
+				"id": "JPNEW",
 				"name": "Newest Port",
 				"coordinates": [
 				  123.321,
@@ -66,9 +390,9 @@ func TestPortsHandlerCorrectResponses(t *testing.T) {
 				"country": "Some Country",
 				"alias": [],
 				"regions": [],
-				"timezone": "My/Timezone",
+				"timezone": "Asia/Tokyo",
 				"unlocs": [
-				  "NEWPORT"
+				  "JPNEW"
 				]
 			}`,
 			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
@@ -79,8 +403,11 @@ func TestPortsHandlerCorrectResponses(t *testing.T) {
 			{
 				"success": true,
 				"exists": false,
-				"port_id": "NEWPORT"
+				"port_id": "JPNEW"
 			}`,
+			expectedResponseHeaders: map[string]string{
+				"ETag": `"1"`,
+			},
 		},
 		{
 			testCaseName: "should return a correct response for updating an existing port",
@@ -92,6 +419,7 @@ func TestPortsHandlerCorrectResponses(t *testing.T) {
 			httpRequestBody: `
 			{
 				"id": "AEAJM",
+				"name": "Ajman",
 				"city": "London",
 				"country": "United Kingdom"
 			}`,
@@ -102,18 +430,47 @@ func TestPortsHandlerCorrectResponses(t *testing.T) {
 				"exists": true,
 				"port_id": "AEAJM"
 			}`,
+			expectedResponseHeaders: map[string]string{
+				"ETag": `"2"`,
+			},
 		},
 		{
-			testCaseName: "should return a correct response for querying an existing port",
+			testCaseName: "should return not modified for querying an unchanged port",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetPortByID,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"If-None-Match": `"2", W/"1"`,
+			},
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetPort()
+			},
+			expectedResponseCode: http.StatusNotModified,
+			expectedResponseHeaders: map[string]string{
+				"ETag":          `"1"`,
+				"Cache-Control": "no-cache",
+			},
+		},
+		{
+			testCaseName: "should return a changed port despite an older If-Modified-Since",
 			httpMethod:   "GET",
 			httpEndpoint: handlers.EndpointGetPortByID,
 			httpPathParams: map[string]string{
 				"id": "AEDXB",
 			},
+			httpHeaders: map[string]string{
+				"If-None-Match":     `"2"`,
+				"If-Modified-Since": "Fri, 01 Jan 2100 00:00:00 GMT",
+			},
 			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
 				return portsHandler.GetPort()
 			},
 			expectedResponseCode: http.StatusOK,
+			expectedResponseHeaders: map[string]string{
+				"ETag": `"1"`,
+			},
 			expectedResponse: `
 			{
 			   "result":{
@@ -137,138 +494,1179 @@ func TestPortsHandlerCorrectResponses(t *testing.T) {
 			}`,
 		},
 		{
-			testCaseName: "should return a correct response for non existent port",
-			httpMethod:   "GET",
-			httpEndpoint: handlers.EndpointGetPortByID,
-			httpPathParams: map[string]string{
-				"id": "NONEXISTENT",
+			testCaseName: "should upsert a batch of ports independently of each other",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointUpsertPorts,
+			httpRequestBody: `
+			[
+				{"id": "JPNEW", "name": "New Port", "country": "Japan"},
+				{"id": "AEAJM", "name": "Ajman Port", "country": "United Arab Emirates"},
+				{"id": "JPBAD"}
+			]`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.UpsertPorts()
 			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+				"created": 1,
+				"updated": 1,
+				"failed": 1,
+				"skipped": 0,
+				"results": [
+					{"id": "JPNEW", "status": "created", "etag": "\"1\""},
+					{"id": "AEAJM", "status": "updated", "etag": "\"2\""},
+					{
+						"id": "JPBAD",
+						"status": "failed",
+						"detail": "name is required; country is required",
+						"errors": [
+							{"field": "name", "message": "name is required"},
+							{"field": "country", "message": "country is required"}
+						]
+					}
+				]
+			}`,
+		},
+		{
+			testCaseName: "should upsert an atomic batch of ports keyed by their IDs",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointUpsertPorts + "?atomic=true",
+			httpRequestBody: `
+			{
+				"JPNEW": {"name": "New Port", "country": "Japan"},
+				"AEAJM": {"name": "Ajman Port", "country": "United Arab Emirates"},
+				"JPNEW": {"name": "Newer Port", "country": "Japan"}
+			}`,
 			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
-				return portsHandler.GetPort()
+				return portsHandler.UpsertPorts()
 			},
-			expectedResponseCode: http.StatusNotFound,
+			expectedResponseCode: http.StatusOK,
 			expectedResponse: `
 			{
-			   "status": 404,
-			   "error": "port entry with ID 'NONEXISTENT' not found"
+				"created": 1,
+				"updated": 2,
+				"failed": 0,
+				"skipped": 0,
+				"results": [
+					{"id": "JPNEW", "status": "created", "etag": "\"1\""},
+					{"id": "AEAJM", "status": "updated", "etag": "\"2\""},
+					{"id": "JPNEW", "status": "updated", "etag": "\"2\""}
+				]
 			}`,
 		},
-	}
-
-	ja := jsonassert.New(t)
-
-	for _, test := range testData {
-		capturedTest := test
-
-		t.Run(capturedTest.testCaseName, func(t *testing.T) {
-			t.Parallel()
-
-			portsHandler := setupHandler(t)
-
-			var reqBody io.Reader
-
-			if len(capturedTest.httpRequestBody) > 0 {
-				reqBody = bytes.NewBuffer([]byte(capturedTest.httpRequestBody))
-			}
-
-			req, errReq := http.NewRequest(capturedTest.httpMethod, capturedTest.httpEndpoint, reqBody)
-			require.NoError(t, errReq)
-
-			rr := httptest.NewRecorder()
-
-			if len(capturedTest.httpPathParams) > 0 {
-				req = mux.SetURLVars(req, capturedTest.httpPathParams)
-			}
-
-			handler := capturedTest.handlerFunc(portsHandler)
-
-			handler.ServeHTTP(rr, req)
-
-			assert.Equal(t, capturedTest.expectedResponseCode, rr.Code)
-
-			verifyExpectedResponse(t, ja, capturedTest.expectedResponse, capturedTest.expectedResponseFileName, rr)
-		})
-	}
-}
-
-func TestPortsHandlerErroneousResponses(t *testing.T) {
-	t.Parallel()
-
-	var testData = []tests{
 		{
-			testCaseName: "should return a validation error for a missing 'id' param " +
-				"in the request body when creating a new port",
+			testCaseName: "should upsert none of the ports of an atomic batch with an invalid port",
 			httpMethod:   "POST",
-			httpEndpoint: handlers.EndpointCreateOrUpdatePort,
+			httpEndpoint: handlers.EndpointUpsertPorts + "?atomic=true",
 			httpRequestBody: `
 			{
-				"name": "Newest Port",
-				"coordinates": [
-				  123.321,
-				  43.34
-				],
-				"city": "Some City",
-				"country": "Some Country",
-				"alias": [],
-				"regions": [],
-				"timezone": "My/Timezone",
-				"unlocs": [
-				  "NEWPORT"
+				"JPNEW": {"name": "New Port", "country": "Japan"},
+				"JPBAD": {"name": "Bad Port"}
+			}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.UpsertPorts()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponseHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			expectedResponse: `
+			{
+				"created": 0,
+				"updated": 0,
+				"failed": 1,
+				"skipped": 1,
+				"results": [
+					{"id": "JPNEW", "status": "skipped"},
+					{
+						"id": "JPBAD",
+						"status": "failed",
+						"detail": "country is required",
+						"errors": [
+							{"field": "country", "message": "country is required"}
+						]
+					}
 				]
 			}`,
+		},
+		{
+			testCaseName: "should report ports of a batch which cannot be decoded as failed",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointUpsertPorts,
+			httpRequestBody: `
+			[
+				{"id": "JPNEW", "name": "New Port", "country": "Japan"},
+				{"id": "JPBAD", "name": 42, "country": "Japan"}
+			]`,
 			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
-				return portsHandler.CreateOrUpdatePort()
+				return portsHandler.UpsertPorts()
 			},
-			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseCode: http.StatusOK,
 			expectedResponse: `
 			{
-				"status": 400,
-				"error": "required body param 'id' is missing"
+				"created": 1,
+				"updated": 0,
+				"failed": 1,
+				"skipped": 0,
+				"results": [
+					{"id": "JPNEW", "status": "created", "etag": "\"1\""},
+					{
+						"id": "JPBAD",
+						"status": "failed",
+						"detail": "could not unmarshal port: ` +
+				`json: cannot unmarshal number into Go struct field MaritimePort.name of type string"
+					}
+				]
 			}`,
 		},
 		{
-			testCaseName: "should return a request body unmarshal error when creating a new port",
+			testCaseName: "should upsert none of the ports of an atomic batch with a port which cannot be decoded",
 			httpMethod:   "POST",
-			httpEndpoint: handlers.EndpointCreateOrUpdatePort,
+			httpEndpoint: handlers.EndpointUpsertPorts + "?atomic=true",
 			httpRequestBody: `
 			{
-				"name": "Newest Port"
-				"coordinates": [
-				  123.321,
-				  43.34
-				],
-				"city": "Some City",
-				"country": "Some Country",
-				"alias": [],
-				"regions": [],
-				"timezone": "My/Timezone",
-				"unlocs": [
-				  "NEWPORT"
+				"JPNEW": {"name": "New Port", "country": "Japan"},
+				"JPBAD": {"name": "Bad Port", "country": "Japan", "coordinates": [1]}
+			}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.UpsertPorts()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponse: `
+			{
+				"created": 0,
+				"updated": 0,
+				"failed": 1,
+				"skipped": 1,
+				"results": [
+					{"id": "JPNEW", "status": "skipped"},
+					{
+						"id": "JPBAD",
+						"status": "failed",
+						"detail": "could not unmarshal port: coordinates must be a [longitude, latitude] pair, got 1 values"
+					}
 				]
 			}`,
+		},
+		{
+			testCaseName: "should export all ports in the shape of the fixtures",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointExportPorts,
 			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
-				return portsHandler.CreateOrUpdatePort()
+				return portsHandler.ExportPorts()
 			},
-			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseCode:     http.StatusOK,
+			expectedResponseFileName: "test_data_ports",
+			expectedResponseHeaders: map[string]string{
+				"Content-Type":        "application/json",
+				"Content-Disposition": `attachment; filename="ports.json"`,
+			},
+		},
+		{
+			testCaseName: "should import ports given as NDJSON",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointImportPorts,
+			httpHeaders: map[string]string{
+				"Content-Type": "application/x-ndjson",
+			},
+			httpRequestBody: `{"id": "JPNEW", "name": "New Port", "country": "Japan"}
+{"id": "AEAJM", "name": "Ajman Port", "country": "United Arab Emirates"}
+`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.ImportPorts()
+			},
+			expectedResponseCode: http.StatusOK,
 			expectedResponse: `
 			{
-				"status": 400,
-				"error": "could not unmarshal request params: invalid character '\"' after object key:value pair"
+				"success": true,
+				"imported": 2
 			}`,
 		},
 		{
-			testCaseName: "should return a validation error for a missing 'id' path param when querying a port by ID",
+			testCaseName: "should import ports given in the shape of the fixtures",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointImportPorts,
+			httpHeaders: map[string]string{
+				"Content-Type": "application/json; charset=utf-8",
+			},
+			httpRequestBody: `{"JPNEW": {"name": "New Port", "country": "Japan"}}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.ImportPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+				"success": true,
+				"imported": 1
+			}`,
+		},
+		{
+			testCaseName: "should return a correct response for querying an existing port",
 			httpMethod:   "GET",
 			httpEndpoint: handlers.EndpointGetPortByID,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
 			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
 				return portsHandler.GetPort()
 			},
-			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseCode: http.StatusOK,
+			expectedResponseHeaders: map[string]string{
+				"ETag":          `"1"`,
+				"Cache-Control": "no-cache",
+			},
 			expectedResponse: `
 			{
-			   "status": 400,
-			   "error": "required path param 'id' is missing"
-			}`,
+			   "result":{
+				  "id":"AEDXB",
+				  "name":"Dubai",
+				  "city":"Dubai",
+				  "country":"United Arab Emirates",
+				  "alias":[],
+				  "regions":[],
+				  "coordinates":[
+					 55.27,
+					 25.25
+				  ],
+				  "province":"Dubayy [Dubai]",
+				  "timezone":"Asia/Dubai",
+				  "unlocs":[
+					 "AEDXB"
+				  ],
+				  "code":"52005"
+			   }
+			}`,
+		},
+		{
+			testCaseName: "should return the coordinates of a port as an object when requested",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetPortByID + "?coords=object",
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetPort()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":{
+				  "id":"AEDXB",
+				  "name":"Dubai",
+				  "city":"Dubai",
+				  "country":"United Arab Emirates",
+				  "alias":[],
+				  "regions":[],
+				  "coordinates":{
+					 "lat":25.25,
+					 "lon":55.27
+				  },
+				  "province":"Dubayy [Dubai]",
+				  "timezone":"Asia/Dubai",
+				  "unlocs":[
+					 "AEDXB"
+				  ],
+				  "code":"52005"
+			   }
+			}`,
+		},
+		{
+			testCaseName: "should return the coordinates of the nearest ports as objects when requested",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetNearestPorts + "?lat=25.25&lon=55.27&k=1&coords=object",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetNearestPorts()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":[
+				  {
+					 "port":{
+						"id":"AEDXB",
+						"name":"Dubai",
+						"city":"Dubai",
+						"country":"United Arab Emirates",
+						"alias":[],
+						"regions":[],
+						"coordinates":{
+						   "lat":25.25,
+						   "lon":55.27
+						},
+						"province":"Dubayy [Dubai]",
+						"timezone":"Asia/Dubai",
+						"unlocs":[
+						   "AEDXB"
+						],
+						"code":"52005"
+					 },
+					 "distance_km":0
+				  }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a correct response for non existent port",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetPortByID,
+			httpPathParams: map[string]string{
+				"id": "NONEXISTENT",
+			},
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetPort()
+			},
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Not Found",
+			   "status": 404,
+			   "detail": "port entry with ID 'NONEXISTENT' not found",
+			   "instance": "/api/v1/ports/{id}"
+			}`,
+		},
+		{
+			testCaseName: "should return a correct response for merge patching an existing port",
+			httpMethod:   "PATCH",
+			httpEndpoint: handlers.EndpointPatchPort,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"Content-Type": "application/merge-patch+json",
+				"If-Match":     `"1"`,
+			},
+			expectedResponseHeaders: map[string]string{
+				"ETag": `"2"`,
+			},
+			httpRequestBody: `
+			{
+				"city": "Dubai City",
+				"alias": ["Dubayy"],
+				"province": null,
+				"code": null
+			}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.PatchPort()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":{
+				  "id":"AEDXB",
+				  "name":"Dubai",
+				  "city":"Dubai City",
+				  "country":"United Arab Emirates",
+				  "alias":["Dubayy"],
+				  "regions":[],
+				  "coordinates":[
+					 55.27,
+					 25.25
+				  ],
+				  "province":"",
+				  "timezone":"Asia/Dubai",
+				  "unlocs":[
+					 "AEDXB"
+				  ]
+			   }
+			}`,
+		},
+		{
+			testCaseName: "should return a correct response for JSON patching an existing port",
+			httpMethod:   "PATCH",
+			httpEndpoint: handlers.EndpointPatchPort,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"Content-Type": "application/json-patch+json",
+			},
+			httpRequestBody: `
+			[
+				{"op": "test", "path": "/code", "value": "52005"},
+				{"op": "remove", "path": "/unlocs"},
+				{"op": "add", "path": "/regions/-", "value": "Persian Gulf"},
+				{"op": "copy", "from": "/city", "path": "/province"}
+			]`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.PatchPort()
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedResponse: `
+			{
+			   "result":{
+				  "id":"AEDXB",
+				  "name":"Dubai",
+				  "city":"Dubai",
+				  "country":"United Arab Emirates",
+				  "alias":[],
+				  "regions":["Persian Gulf"],
+				  "coordinates":[
+					 55.27,
+					 25.25
+				  ],
+				  "province":"Dubai",
+				  "timezone":"Asia/Dubai",
+				  "unlocs":[],
+				  "code":"52005"
+			   }
+			}`,
+		},
+		{
+			testCaseName: "should return a correct response for patching a non existent port",
+			httpMethod:   "PATCH",
+			httpEndpoint: handlers.EndpointPatchPort,
+			httpPathParams: map[string]string{
+				"id": "NONEXISTENT",
+			},
+			httpHeaders: map[string]string{
+				"Content-Type": "application/merge-patch+json",
+			},
+			httpRequestBody: `{"city": "Nowhere"}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.PatchPort()
+			},
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Not Found",
+			   "status": 404,
+			   "detail": "port entry with ID 'NONEXISTENT' not found",
+			   "instance": "/api/v1/ports/{id}"
+			}`,
+		},
+		{
+			testCaseName: "should return a correct response for deleting an existing port",
+			httpMethod:   "DELETE",
+			httpEndpoint: handlers.EndpointDeletePort,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"If-Match": `"1"`,
+			},
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.DeletePort()
+			},
+			expectedResponseCode: http.StatusNoContent,
+		},
+		{
+			testCaseName: "should return a correct response for deleting a non existent port",
+			httpMethod:   "DELETE",
+			httpEndpoint: handlers.EndpointDeletePort,
+			httpPathParams: map[string]string{
+				"id": "NONEXISTENT",
+			},
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.DeletePort()
+			},
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Not Found",
+			   "status": 404,
+			   "detail": "port entry with ID 'NONEXISTENT' not found",
+			   "instance": "/api/v1/ports/{id}"
+			}`,
+		},
+	}
+
+	ja := jsonassert.New(t)
+
+	for _, test := range testData {
+		capturedTest := test
+
+		t.Run(capturedTest.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			portsHandler := setupHandler(t)
+
+			var reqBody io.Reader
+
+			if len(capturedTest.httpRequestBody) > 0 {
+				reqBody = bytes.NewBuffer([]byte(capturedTest.httpRequestBody))
+			}
+
+			req, errReq := http.NewRequest(capturedTest.httpMethod, capturedTest.httpEndpoint, reqBody)
+			require.NoError(t, errReq)
+
+			rr := httptest.NewRecorder()
+
+			for k, v := range capturedTest.httpHeaders {
+				req.Header.Set(k, v)
+			}
+
+			if len(capturedTest.httpPathParams) > 0 {
+				req = mux.SetURLVars(req, capturedTest.httpPathParams)
+			}
+
+			handler := capturedTest.handlerFunc(portsHandler)
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, capturedTest.expectedResponseCode, rr.Code)
+
+			for k, v := range capturedTest.expectedResponseHeaders {
+				assert.Equal(t, v, rr.Header().Get(k), k)
+			}
+
+			verifyExpectedResponse(t, ja, capturedTest.expectedResponse, capturedTest.expectedResponseFileName, rr)
+		})
+	}
+}
+
+func TestPortsHandlerErroneousResponses(t *testing.T) {
+	t.Parallel()
+
+	var testData = []tests{
+		{
+			testCaseName: "should return a validation error for a missing 'id' param " +
+				"in the request body when creating a new port",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointCreateOrUpdatePort,
+			httpRequestBody: `
+			{
+				"name": "Newest Port",
+				"coordinates": [
+				  123.321,
+				  43.34
+				],
+				"city": "Some City",
+				"country": "Some Country",
+				"alias": [],
+				"regions": [],
+				"timezone": "Asia/Tokyo",
+				"unlocs": [
+				  "JPNEW"
+				]
+			}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.CreateOrUpdatePort()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "required body param 'id' is missing",
+				"instance": "/api/v1/ports",
+				"errors": [
+				   {
+				      "field": "id",
+				      "message": "required body param 'id' is missing"
+				   }
+				]
+			}`,
+		},
+		{
+			testCaseName: "should return a request body unmarshal error when creating a new port",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointCreateOrUpdatePort,
+			httpRequestBody: `
+			{
+				"name": "Newest Port"
+				"coordinates": [
+				  123.321,
+				  43.34
+				],
+				"city": "Some City",
+				"country": "Some Country",
+				"alias": [],
+				"regions": [],
+				"timezone": "Asia/Tokyo",
+				"unlocs": [
+				  "JPNEW"
+				]
+			}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.CreateOrUpdatePort()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "could not unmarshal request params: invalid character '\"' after object key:value pair",
+				"instance": "/api/v1/ports"
+			}`,
+		},
+		{
+			testCaseName:    "should return an error for an invalid 'atomic' param when upserting a batch of ports",
+			httpMethod:      "POST",
+			httpEndpoint:    handlers.EndpointUpsertPorts + "?atomic=maybe",
+			httpRequestBody: `[]`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.UpsertPorts()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "query param 'atomic' must be a boolean, got 'maybe'",
+				"instance": "/api/v1/ports:batch",
+				"errors": [
+				   {
+				      "field": "atomic",
+				      "message": "query param 'atomic' must be a boolean, got 'maybe'"
+				   }
+				]
+			}`,
+		},
+		{
+			testCaseName:    "should return an error for a batch of ports which is neither an array nor an object",
+			httpMethod:      "POST",
+			httpEndpoint:    handlers.EndpointUpsertPorts,
+			httpRequestBody: `"AEAJM"`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.UpsertPorts()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "request body must be a JSON array or object of ports",
+				"instance": "/api/v1/ports:batch"
+			}`,
+		},
+		{
+			testCaseName:    "should return an error for a batch of ports which is too large",
+			httpMethod:      "POST",
+			httpEndpoint:    handlers.EndpointUpsertPorts,
+			httpRequestBody: "[" + strings.Repeat(`{"id": "JPNEW", "name": "New Port", "country": "Japan"},`, 200000) + "{}]",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.UpsertPorts()
+			},
+			expectedResponseCode: http.StatusRequestEntityTooLarge,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Request Entity Too Large",
+				"status": 413,
+				"detail": "could not unmarshal request body: http: request body too large",
+				"instance": "/api/v1/ports:batch"
+			}`,
+		},
+		{
+			testCaseName: "should return an error for an unsupported 'format' param when exporting ports",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointExportPorts + "?format=csv",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.ExportPorts()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "query param 'format' must be either 'json' or 'ndjson', got 'csv'",
+				"instance": "/api/v1/ports/export",
+				"errors": [
+				   {
+				      "field": "format",
+				      "message": "query param 'format' must be either 'json' or 'ndjson', got 'csv'"
+				   }
+				]
+			}`,
+		},
+		{
+			testCaseName: "should return an error for an unsupported content type when importing ports",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointImportPorts,
+			httpHeaders: map[string]string{
+				"Content-Type": "text/csv",
+			},
+			httpRequestBody: `id,name`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.ImportPorts()
+			},
+			expectedResponseCode: http.StatusUnsupportedMediaType,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Unsupported Media Type",
+				"status": 415,
+				"detail": "unsupported content type 'text/csv', expected 'application/json' or 'application/x-ndjson'",
+				"instance": "/api/v1/ports/import"
+			}`,
+		},
+		{
+			testCaseName: "should stop importing ports at the first invalid port",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointImportPorts,
+			httpHeaders: map[string]string{
+				"Content-Type": "application/x-ndjson",
+			},
+			httpRequestBody: `{"id": "JPNEW", "name": "New Port", "country": "Japan"}
+{"id": "JPBAD", "name": "Bad Port"}
+{"id": "JPOTH", "name": "Other Port", "country": "Japan"}
+`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.ImportPorts()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Unprocessable Entity",
+				"status": 422,
+				"detail": "could not import ports after importing 1 ports: invalid port entry with ID 'JPBAD': country is required",
+				"instance": "/api/v1/ports/import",
+				"errors": [
+				   {
+				      "field": "country",
+				      "message": "country is required"
+				   }
+				]
+			}`,
+		},
+		{
+			testCaseName:    "should return an error for malformed ports data when importing ports",
+			httpMethod:      "POST",
+			httpEndpoint:    handlers.EndpointImportPorts,
+			httpRequestBody: `["AEAJM"]`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.ImportPorts()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Unprocessable Entity",
+				"status": 422,
+				"detail": "could not import ports after importing 0 ports: ports data must be a JSON object keyed by port IDs",
+				"instance": "/api/v1/ports/import"
+			}`,
+		},
+		{
+			testCaseName: "should return all validation errors when creating an invalid port",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointCreateOrUpdatePort,
+			httpRequestBody: `
+			{
+				"id": "NEWPORT",
+				"name": "Newest Port",
+				"coordinates": [
+				  123.321,
+				  95
+				],
+				"city": "Some City",
+				"country": "",
+				"alias": [],
+				"regions": [],
+				"timezone": "My/Timezone",
+				"unlocs": [
+				  "JPNEW",
+				  "jp new"
+				]
+			}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.CreateOrUpdatePort()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponse: `
+			{
+				"type": "about:blank",
+				"title": "Unprocessable Entity",
+				"status": 422,
+				"detail": "<<PRESENCE>>",
+				"instance": "/api/v1/ports",
+				"errors": [
+				   {
+				      "field": "id",
+				      "message": "id must be a valid UN/LOCODE, got 'NEWPORT'"
+				   },
+				   {
+				      "field": "country",
+				      "message": "country is required"
+				   },
+				   {
+				      "field": "coordinates",
+				      "message": "latitude must be between -90 and 90, got 95"
+				   },
+				   {
+				      "field": "timezone",
+				      "message": "timezone must be an IANA time zone name, got 'My/Timezone'"
+				   },
+				   {
+				      "field": "unlocs[1]",
+				      "message": "unlocs must be valid UN/LOCODEs, got 'jp new'"
+				   }
+				]
+			}`,
+		},
+		{
+			testCaseName: "should return a precondition error when updating a port with a stale ETag",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointCreateOrUpdatePort,
+			httpHeaders: map[string]string{
+				"If-Match": `"2"`,
+			},
+			httpRequestBody: `{"id": "AEAJM", "name": "Ajman", "country": "United Arab Emirates"}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.CreateOrUpdatePort()
+			},
+			expectedResponseCode: http.StatusPreconditionFailed,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Precondition Failed",
+			   "status": 412,
+			   "detail": "could not create/update port: port entry with ID 'AEAJM' has changed, its current version is 1",
+			   "instance": "/api/v1/ports"
+			}`,
+		},
+		{
+			testCaseName: "should return a precondition error when creating a port which already exists",
+			httpMethod:   "POST",
+			httpEndpoint: handlers.EndpointCreateOrUpdatePort,
+			httpHeaders: map[string]string{
+				"If-None-Match": "*",
+			},
+			httpRequestBody: `{"id": "AEAJM", "name": "Ajman", "country": "United Arab Emirates"}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.CreateOrUpdatePort()
+			},
+			expectedResponseCode: http.StatusPreconditionFailed,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Precondition Failed",
+			   "status": 412,
+			   "detail": "could not create/update port: port entry with ID 'AEAJM' already exists",
+			   "instance": "/api/v1/ports"
+			}`,
+		},
+		{
+			testCaseName: "should return a precondition error when deleting a port with a stale ETag",
+			httpMethod:   "DELETE",
+			httpEndpoint: handlers.EndpointDeletePort,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"If-Match": `"0", "2"`,
+			},
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.DeletePort()
+			},
+			expectedResponseCode: http.StatusPreconditionFailed,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Precondition Failed",
+			   "status": 412,
+			   "detail": "port entry with ID 'AEDXB' has changed, its current version is 1",
+			   "instance": "/api/v1/ports/{id}"
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for a malformed ETag when patching a port",
+			httpMethod:   "PATCH",
+			httpEndpoint: handlers.EndpointPatchPort,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"Content-Type": "application/merge-patch+json",
+				"If-Match":     "1",
+			},
+			httpRequestBody: `{"city": "Dubai City"}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.PatchPort()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Bad Request",
+			   "status": 400,
+			   "detail": "header 'If-Match' must be '*' or a list of entity tags, got '1'",
+			   "instance": "/api/v1/ports/{id}",
+			   "errors": [
+			      {
+			         "field": "If-Match",
+			         "message": "header 'If-Match' must be '*' or a list of entity tags, got '1'"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for a missing 'id' path param when querying a port by ID",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetPortByID,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetPort()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Bad Request",
+			   "status": 400,
+			   "detail": "required path param 'id' is missing",
+			   "instance": "/api/v1/ports/{id}",
+			   "errors": [
+			      {
+			         "field": "id",
+			         "message": "required path param 'id' is missing"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for an unsupported sort field when getting all ports",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetAllPorts + "?sort=unknown",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetAllPorts()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Unprocessable Entity",
+			   "status": 422,
+			   "detail": "could not get all ports: unsupported sort field 'unknown'",
+			   "instance": "/api/v1/ports",
+			   "errors": [
+			      {
+			         "field": "sort",
+			         "message": "unsupported sort field 'unknown'"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for a malformed bounding box when getting ports within",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetPortsWithin + "?bbox=1,2,3",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetPortsWithin()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Bad Request",
+			   "status": 400,
+			   "detail": "query param 'bbox' must be a list of 4 comma separated numbers, got '1,2,3'",
+			   "instance": "/api/v1/ports/within",
+			   "errors": [
+			      {
+			         "field": "bbox",
+			         "message": "query param 'bbox' must be a list of 4 comma separated numbers, got '1,2,3'"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for a missing latitude when getting the nearest ports",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetNearestPorts + "?lon=55.27",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetNearestPorts()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Bad Request",
+			   "status": 400,
+			   "detail": "required query param 'lat' is missing",
+			   "instance": "/api/v1/ports/nearest",
+			   "errors": [
+			      {
+			         "field": "lat",
+			         "message": "required query param 'lat' is missing"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for an unknown coordinates format",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointGetPortByID + "?coords=pair",
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.GetPort()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Bad Request",
+			   "status": 400,
+			   "detail": "query param 'coords' must be either 'array' or 'object', got 'pair'",
+			   "instance": "/api/v1/ports/{id}",
+			   "errors": [
+			      {
+			         "field": "coords",
+			         "message": "query param 'coords' must be either 'array' or 'object', got 'pair'"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for a missing search query",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointSearchPorts,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.SearchPorts()
+			},
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Bad Request",
+			   "status": 400,
+			   "detail": "required query param 'q' is missing",
+			   "instance": "/api/v1/ports/search",
+			   "errors": [
+			      {
+			         "field": "q",
+			         "message": "required query param 'q' is missing"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for a search query with too many terms",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointSearchPorts + "?q=a+b+c+d+e+f+g+h+i+j+k",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.SearchPorts()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Unprocessable Entity",
+			   "status": 422,
+			   "detail": "could not search ports: search query must not contain more than 10 terms, got 11",
+			   "instance": "/api/v1/ports/search",
+			   "errors": [
+			      {
+			         "field": "q",
+			         "message": "search query must not contain more than 10 terms, got 11"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error for a suggestion limit out of range",
+			httpMethod:   "GET",
+			httpEndpoint: handlers.EndpointSuggestPorts + "?prefix=a&limit=51",
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.SuggestPorts()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Unprocessable Entity",
+			   "status": 422,
+			   "detail": "could not suggest ports: limit must be between 1 and 50",
+			   "instance": "/api/v1/ports/suggest",
+			   "errors": [
+			      {
+			         "field": "limit",
+			         "message": "limit must be between 1 and 50"
+			      }
+			   ]
+			}`,
+		},
+		{
+			testCaseName: "should return an unsupported media type error when patching a port with plain JSON",
+			httpMethod:   "PATCH",
+			httpEndpoint: handlers.EndpointPatchPort,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			httpRequestBody: `{"city": "Dubai City"}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.PatchPort()
+			},
+			expectedResponseCode: http.StatusUnsupportedMediaType,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Unsupported Media Type",
+			   "status": 415,
+			   "detail": "unsupported content type 'application/json', expected 'application/merge-patch+json' or 'application/json-patch+json'",
+			   "instance": "/api/v1/ports/{id}"
+			}`,
+		},
+		{
+			testCaseName: "should return a conflict error when a JSON patch test operation fails",
+			httpMethod:   "PATCH",
+			httpEndpoint: handlers.EndpointPatchPort,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"Content-Type": "application/json-patch+json",
+			},
+			httpRequestBody: `[
+				{"op": "test", "path": "/city", "value": "Abu Dhabi"},
+				{"op": "replace", "path": "/city", "value": "Dubai City"}
+			]`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.PatchPort()
+			},
+			expectedResponseCode: http.StatusConflict,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Conflict",
+			   "status": 409,
+			   "detail": "could not patch port entry with ID 'AEDXB': JSON patch operation 0 (test) failed: test failed: value at '/city' does not match",
+			   "instance": "/api/v1/ports/{id}"
+			}`,
+		},
+		{
+			testCaseName: "should return a validation error when patching the ID of a port",
+			httpMethod:   "PATCH",
+			httpEndpoint: handlers.EndpointPatchPort,
+			httpPathParams: map[string]string{
+				"id": "AEDXB",
+			},
+			httpHeaders: map[string]string{
+				"Content-Type": "application/merge-patch+json",
+			},
+			httpRequestBody: `{"id": "AEXXX"}`,
+			handlerFunc: func(portsHandler *handlers.PortsHandler) http.HandlerFunc {
+				return portsHandler.PatchPort()
+			},
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponse: `
+			{
+			   "type": "about:blank",
+			   "title": "Unprocessable Entity",
+			   "status": 422,
+			   "detail": "could not patch port entry with ID 'AEDXB': patch cannot change port ID 'AEDXB'",
+			   "instance": "/api/v1/ports/{id}",
+			   "errors": [
+			      {
+			         "field": "id",
+			         "message": "patch cannot change port ID 'AEDXB'"
+			      }
+			   ]
+			}`,
 		},
 	}
 
@@ -293,6 +1691,10 @@ func TestPortsHandlerErroneousResponses(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 
+			for k, v := range capturedTest.httpHeaders {
+				req.Header.Set(k, v)
+			}
+
 			if len(capturedTest.httpPathParams) > 0 {
 				req = mux.SetURLVars(req, capturedTest.httpPathParams)
 			}
@@ -303,18 +1705,259 @@ func TestPortsHandlerErroneousResponses(t *testing.T) {
 
 			assert.Equal(t, capturedTest.expectedResponseCode, rr.Code)
 
+			for k, v := range capturedTest.expectedResponseHeaders {
+				assert.Equal(t, v, rr.Header().Get(k), k)
+			}
+			assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+
 			verifyExpectedResponse(t, ja, capturedTest.expectedResponse, capturedTest.expectedResponseFileName, rr)
 		})
 	}
 }
 
+// failingPortsStore is a portsmanaging.PortsStore failing all upserts with err.
+type failingPortsStore struct {
+	*memory.PortsRepository
+	err error
+}
+
+func (s *failingPortsStore) UpsertPort(
+	context.Context,
+	*portsmanaging.MaritimePort,
+	portsmanaging.Precondition,
+) (*portsmanaging.MaritimePort, bool, error) {
+	return nil, false, s.err
+}
+
+func TestPortsHandlerStoreErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return an internal server error without the cause for corrupt data", func(t *testing.T) {
+		portsStore := &failingPortsStore{
+			PortsRepository: memory.NewPortsRepository(),
+			err:             portsmanaging.NewError(portsmanaging.ErrCorrupt, "error: updated port entry is corrupt"),
+		}
+		portsHandler := handlers.NewPortsHandler(portsmanaging.NewService(portsStore), "")
+
+		reqBody := bytes.NewBufferString(`{"id": "AEDXB", "name": "Dubai", "country": "United Arab Emirates"}`)
+		req, err := http.NewRequest("POST", handlers.EndpointCreateOrUpdatePort, reqBody)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		portsHandler.CreateOrUpdatePort().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+		jsonassert.New(t).Assertf(rr.Body.String(), `
+		{
+		   "type": "about:blank",
+		   "title": "Internal Server Error",
+		   "status": 500,
+		   "detail": "internal server error",
+		   "instance": "/api/v1/ports"
+		}`)
+	})
+}
+
+func TestPortsHandlerConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	get := func(portsHandler *handlers.PortsHandler, headers map[string]string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", handlers.EndpointGetAllPorts, nil)
+		require.NoError(t, err)
+
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		rr := httptest.NewRecorder()
+		portsHandler.GetAllPorts().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	t.Run("should return not modified for an unchanged list of ports", func(t *testing.T) {
+		portsHandler := setupHandler(t)
+
+		rr := get(portsHandler, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		eTag := rr.Header().Get("ETag")
+		lastModified := rr.Header().Get("Last-Modified")
+		assert.NotEmpty(t, eTag)
+		assert.NotEmpty(t, lastModified)
+		assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+
+		rr = get(portsHandler, map[string]string{"If-None-Match": eTag})
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Equal(t, eTag, rr.Header().Get("ETag"))
+		assert.Empty(t, rr.Body.String())
+
+		rr = get(portsHandler, map[string]string{"If-Modified-Since": lastModified})
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Empty(t, rr.Body.String())
+
+		rr = get(portsHandler, map[string]string{"If-None-Match": `"r1-0"`, "If-Modified-Since": lastModified})
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should validate the list options before checking whether the list of ports changed", func(t *testing.T) {
+		portsHandler := setupHandler(t)
+
+		rr := get(portsHandler, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		req, err := http.NewRequest("GET", handlers.EndpointGetAllPorts+"?sort=bogus", nil)
+		require.NoError(t, err)
+
+		req.Header.Set("If-Modified-Since", rr.Header().Get("Last-Modified"))
+
+		rr = httptest.NewRecorder()
+		portsHandler.GetAllPorts().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("should return the list of ports after any port has changed", func(t *testing.T) {
+		portsHandler := setupHandler(t)
+
+		rr := get(portsHandler, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		eTag := rr.Header().Get("ETag")
+
+		req, err := http.NewRequest("DELETE", handlers.EndpointDeletePort, nil)
+		require.NoError(t, err)
+
+		req = mux.SetURLVars(req, map[string]string{"id": "AEDXB"})
+		portsHandler.DeletePort().ServeHTTP(httptest.NewRecorder(), req)
+
+		rr = get(portsHandler, map[string]string{"If-None-Match": eTag})
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotEqual(t, eTag, rr.Header().Get("ETag"))
+	})
+
+	t.Run("should not reuse the versions of a deleted port once it is created again", func(t *testing.T) {
+		portsHandler := setupHandler(t)
+
+		serve := func(
+			handler http.HandlerFunc,
+			method string,
+			body string,
+			headers map[string]string,
+		) *httptest.ResponseRecorder {
+			req, err := http.NewRequest(method, handlers.EndpointGetPortByID, strings.NewReader(body))
+			require.NoError(t, err)
+
+			req = mux.SetURLVars(req, map[string]string{"id": "AEDXB"})
+
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		rr := serve(portsHandler.DeletePort(), "DELETE", "", map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		body := `{"id": "AEDXB", "name": "Dubai", "country": "United Arab Emirates"}`
+
+		rr = serve(portsHandler.CreateOrUpdatePort(), "POST", body, map[string]string{
+			"Content-Type": "application/json",
+			"If-Match":     `"1"`,
+		})
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+
+		rr = serve(portsHandler.CreateOrUpdatePort(), "POST", body, map[string]string{
+			"Content-Type":  "application/json",
+			"If-None-Match": "*",
+		})
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+		rr = serve(portsHandler.GetPort(), "GET", "", map[string]string{"If-None-Match": `"1"`})
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+		rr = serve(portsHandler.CreateOrUpdatePort(), "POST", body, map[string]string{
+			"Content-Type": "application/json",
+			"If-Match":     `"1"`,
+		})
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	})
+
+	t.Run("should never match weak entity tags when updating a port", func(t *testing.T) {
+		portsHandler := setupHandler(t)
+
+		update := func(ifMatch string) *httptest.ResponseRecorder {
+			req, err := http.NewRequest("POST", handlers.EndpointCreateOrUpdatePort, strings.NewReader(
+				`{"id": "AEDXB", "name": "Dubai", "country": "United Arab Emirates"}`))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", ifMatch)
+
+			rr := httptest.NewRecorder()
+			portsHandler.CreateOrUpdatePort().ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		rr := update(`W/"1"`)
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+
+		rr = update(`W/"1", "1"`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+	})
+}
+
+func TestPortsHandlerExportNDJSON(t *testing.T) {
+	t.Parallel()
+
+	portsHandler := setupHandler(t)
+
+	req, err := http.NewRequest("GET", handlers.EndpointExportPorts+"?format=ndjson", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	portsHandler.ExportPorts().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+
+	for i, id := range []string{"AEAJM", "AEAUH", "AEDXB"} {
+		jsonassert.New(t).Assertf(lines[i], `{
+			"id": "%s",
+			"name": "<<PRESENCE>>",
+			"city": "<<PRESENCE>>",
+			"country": "United Arab Emirates",
+			"alias": [],
+			"regions": [],
+			"coordinates": "<<PRESENCE>>",
+			"province": "<<PRESENCE>>",
+			"timezone": "Asia/Dubai",
+			"unlocs": ["%s"],
+			"code": "<<PRESENCE>>"
+		}`, id, id)
+	}
+}
+
 func setupHandler(t *testing.T) *handlers.PortsHandler {
 	portsStore := memory.NewPortsRepository()
 	portsService := portsmanaging.NewService(portsStore)
-	portsHandler := handlers.NewPortsHandler(portsService)
+	portsHandler := handlers.NewPortsHandler(portsService, "no-cache")
 	loader := portsmanaging.NewJSONLoader(portsStore)
 
-	err := loader.LoadJSONFile("../../testdata/test_data_ports.json")
+	_, err := loader.LoadJSONFile(context.Background(), "../../testdata/test_data_ports.json")
 	require.NoError(t, err)
 
 	return portsHandler
@@ -329,6 +1972,12 @@ func verifyExpectedResponse(
 ) {
 	var expected []byte
 
+	if len(expectedResponse) == 0 && len(expectedResponseFileName) == 0 {
+		assert.Empty(t, respRec.Body.String())
+
+		return
+	}
+
 	if len(expectedResponseFileName) > 0 {
 		filePath, errFilePath := filepath.Abs(fmt.Sprintf("../../testdata/%s.json", expectedResponseFileName))
 
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+const mediaTypeProblem = "application/problem+json"
+
+// errInternal is reported instead of the cause of internal server errors, which is only logged.
+var errInternal = errors.New("internal server error")
+
+// Problem represents an RFC 7807 problem details response body. Problems are not
+// further classified by type, so Type is always "about:blank" and Title is the
+// standard text of the status code.
+type Problem struct {
+	Type     string                    `json:"type" example:"about:blank"`
+	Title    string                    `json:"title" example:"Unprocessable Entity"`
+	Status   int                       `json:"status" example:"422"`
+	Detail   string                    `json:"detail,omitempty" example:"k must be between 1 and 100"`
+	Instance string                    `json:"instance,omitempty" example:"/api/v1/ports/nearest"`
+	Errors   []portsmanaging.Violation `json:"errors,omitempty"`
+}
+
+// paramError represents an invalid request parameter.
+type paramError struct {
+	portsmanaging.Violation
+}
+
+func newParamError(param string, message string) error {
+	return &paramError{
+		Violation: portsmanaging.Violation{Field: param, Message: message},
+	}
+}
+
+// Error returns the violation message.
+func (e *paramError) Error() string {
+	return e.Message
+}
+
+// newProblem builds the problem details of err for the request r. The field violations
+// are taken from validation errors and from invalid request parameters.
+func newProblem(r *http.Request, statusCode int, err error) *Problem {
+	violations := portsmanaging.Violations(err)
+
+	var pe *paramError
+	if len(violations) == 0 && errors.As(err, &pe) {
+		violations = []portsmanaging.Violation{pe.Violation}
+	}
+
+	return &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Detail:   err.Error(),
+		Instance: r.URL.Path,
+		Errors:   violations,
+	}
+}
+
+// writeProblem responds with the problem details of err.
+func writeProblem(rw http.ResponseWriter, r *http.Request, statusCode int, err error) {
+	problemBytes, errMarshal := json.Marshal(newProblem(r, statusCode, err))
+	if errMarshal != nil {
+		http.Error(rw, errMarshal.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", mediaTypeProblem)
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(statusCode)
+
+	if _, errWrite := rw.Write(problemBytes); errWrite != nil {
+		http.Error(rw, errWrite.Error(), http.StatusInternalServerError)
+	}
+}
+
+func badRequestError(rw http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(rw, r, http.StatusBadRequest, err)
+}
+
+func unsupportedMediaTypeError(rw http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(rw, r, http.StatusUnsupportedMediaType, err)
+}
+
+// handleError responds with the status code matching the kind of err: 404 for
+// portsmanaging.ErrNotFound, 422 for portsmanaging.ErrValidation, 409 for
+// portsmanaging.ErrConflict, 412 for portsmanaging.ErrPreconditionFailed and 500
+// for portsmanaging.ErrCorrupt and all other errors, whose cause is logged rather than
+// exposed to the client.
+func handleError(rw http.ResponseWriter, r *http.Request, err error) {
+	status := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("[%s %s] %v\n", r.Method, r.URL.Path, err)
+
+		err = errInternal
+	}
+
+	writeProblem(rw, r, status, err)
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, portsmanaging.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, portsmanaging.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, portsmanaging.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, portsmanaging.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
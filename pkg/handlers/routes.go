@@ -12,10 +12,28 @@ import (
 const (
 	// EndpointCreateOrUpdatePort is an HTTP endpoint for create or update port operation.
 	EndpointCreateOrUpdatePort = "/api/v1/ports"
+	// EndpointUpsertPorts is an HTTP endpoint for creating or updating a batch of ports operation.
+	EndpointUpsertPorts = "/api/v1/ports:batch"
 	// EndpointGetAllPorts is an HTTP endpoint for getting all ports operation.
 	EndpointGetAllPorts = "/api/v1/ports"
+	// EndpointGetNearestPorts is an HTTP endpoint for getting the ports nearest to a location operation.
+	EndpointGetNearestPorts = "/api/v1/ports/nearest"
+	// EndpointGetPortsWithin is an HTTP endpoint for getting the ports inside an area operation.
+	EndpointGetPortsWithin = "/api/v1/ports/within"
+	// EndpointSearchPorts is an HTTP endpoint for searching ports by free text operation.
+	EndpointSearchPorts = "/api/v1/ports/search"
+	// EndpointSuggestPorts is an HTTP endpoint for suggesting ports by prefix operation.
+	EndpointSuggestPorts = "/api/v1/ports/suggest"
+	// EndpointImportPorts is an HTTP endpoint for importing ports data operation.
+	EndpointImportPorts = "/api/v1/ports/import"
+	// EndpointExportPorts is an HTTP endpoint for exporting ports data operation.
+	EndpointExportPorts = "/api/v1/ports/export"
 	// EndpointGetPortByID is an HTTP endpoint for getting a port by ID operation.
 	EndpointGetPortByID = "/api/v1/ports/{id}"
+	// EndpointPatchPort is an HTTP endpoint for partially updating a port by ID operation.
+	EndpointPatchPort = "/api/v1/ports/{id}"
+	// EndpointDeletePort is an HTTP endpoint for deleting a port by ID operation.
+	EndpointDeletePort = "/api/v1/ports/{id}"
 )
 
 func registerHTTPRoutes(
@@ -23,9 +41,36 @@ func registerHTTPRoutes(
 	muxer.HandleFunc(
 		EndpointCreateOrUpdatePort,
 		handler.CreateOrUpdatePort()).Methods("POST")
+	muxer.HandleFunc(
+		EndpointUpsertPorts,
+		handler.UpsertPorts()).Methods("POST")
+	muxer.HandleFunc(
+		EndpointGetNearestPorts,
+		handler.GetNearestPorts()).Methods("GET")
+	muxer.HandleFunc(
+		EndpointGetPortsWithin,
+		handler.GetPortsWithin()).Methods("GET")
+	muxer.HandleFunc(
+		EndpointSearchPorts,
+		handler.SearchPorts()).Methods("GET")
+	muxer.HandleFunc(
+		EndpointSuggestPorts,
+		handler.SuggestPorts()).Methods("GET")
+	muxer.HandleFunc(
+		EndpointImportPorts,
+		handler.ImportPorts()).Methods("POST")
+	muxer.HandleFunc(
+		EndpointExportPorts,
+		handler.ExportPorts()).Methods("GET")
 	muxer.HandleFunc(
 		EndpointGetPortByID,
 		handler.GetPort()).Methods("GET")
+	muxer.HandleFunc(
+		EndpointPatchPort,
+		handler.PatchPort()).Methods("PATCH")
+	muxer.HandleFunc(
+		EndpointDeletePort,
+		handler.DeletePort()).Methods("DELETE")
 	muxer.HandleFunc(
 		EndpointGetAllPorts,
 		handler.GetAllPorts()).Methods("GET")
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+const (
+	mediaTypeJSON   = "application/json"
+	mediaTypeNDJSON = "application/x-ndjson"
+)
+
+// importFormat returns the format of imported ports data given its content type,
+// which defaults to the format of the ports fixtures.
+func importFormat(contentType string) (portsmanaging.DataFormat, error) {
+	if contentType == "" {
+		return portsmanaging.FixturesJSON, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, fmt.Errorf("invalid content type '%s'", contentType)
+	}
+
+	switch mediaType {
+	case mediaTypeJSON:
+		return portsmanaging.FixturesJSON, nil
+	case mediaTypeNDJSON:
+		return portsmanaging.NDJSON, nil
+	default:
+		return 0, fmt.Errorf(
+			"unsupported content type '%s', expected '%s' or '%s'",
+			mediaType, mediaTypeJSON, mediaTypeNDJSON,
+		)
+	}
+}
+
+// exportFormat returns the format of exported ports data requested by the 'format'
+// query param together with its media type and file name.
+func exportFormat(query url.Values) (portsmanaging.DataFormat, string, string, error) {
+	switch v := query.Get("format"); v {
+	case "", "json":
+		return portsmanaging.FixturesJSON, mediaTypeJSON, "ports.json", nil
+	case "ndjson":
+		return portsmanaging.NDJSON, mediaTypeNDJSON, "ports.ndjson", nil
+	default:
+		return 0, "", "", newParamError(
+			"format", fmt.Sprintf("query param 'format' must be either 'json' or 'ndjson', got '%s'", v))
+	}
+}
+
+// countingWriter counts the bytes written to the underlying writer, so that it is known
+// whether a response has already been started.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write writes to the underlying writer.
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,76 @@
+package portsmanaging
+
+// UpsertStatus represents the outcome of upserting a single port of a batch.
+type UpsertStatus string
+
+const (
+	// UpsertCreated means that the port did not exist and has been created.
+	UpsertCreated UpsertStatus = "created"
+	// UpsertUpdated means that the port existed and has been updated.
+	UpsertUpdated UpsertStatus = "updated"
+	// UpsertFailed means that the port has not been upserted because of an error.
+	UpsertFailed UpsertStatus = "failed"
+	// UpsertSkipped means that the port has not been upserted because another port of
+	// an atomic batch failed.
+	UpsertSkipped UpsertStatus = "skipped"
+)
+
+// BatchItem represents a single port of a batch to be upserted.
+type BatchItem struct {
+	Port *MaritimePort
+	// Err is the reason why the port could not be decoded, in which case Port holds
+	// the data decoded so far, if any, and is not upserted.
+	Err error
+}
+
+// UpsertResult represents the outcome of upserting a single port of a batch.
+type UpsertResult struct {
+	ID     string
+	Status UpsertStatus
+	// Port is the upserted port, if created or updated.
+	Port *MaritimePort
+	// Err is the reason why the port failed.
+	Err error
+}
+
+// FailedUpsert returns the UpsertResult of a port which failed with err.
+func FailedUpsert(id string, err error) *UpsertResult {
+	return &UpsertResult{ID: id, Status: UpsertFailed, Err: err}
+}
+
+// SucceededUpsert returns the UpsertResult of a port which has been created, or updated if it existed.
+func SucceededUpsert(port *MaritimePort, existed bool) *UpsertResult {
+	status := UpsertCreated
+	if existed {
+		status = UpsertUpdated
+	}
+
+	return &UpsertResult{ID: port.ID, Status: status, Port: port}
+}
+
+// SkipUpserts marks all results of an atomic batch which did not fail as skipped and
+// reports whether there were any failures.
+func SkipUpserts(results []*UpsertResult) bool {
+	failed := false
+
+	for _, res := range results {
+		if res.Status == UpsertFailed {
+			failed = true
+
+			break
+		}
+	}
+
+	if !failed {
+		return false
+	}
+
+	for _, res := range results {
+		if res.Status != UpsertFailed {
+			res.Status = UpsertSkipped
+			res.Port = nil
+		}
+	}
+
+	return true
+}
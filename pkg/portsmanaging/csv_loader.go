@@ -0,0 +1,285 @@
+package portsmanaging
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// CSVColumns maps the headers of CSV columns to MaritimePort fields. Headers are matched
+// regardless of case and surrounding whitespace. An empty header leaves the field unmapped.
+type CSVColumns struct {
+	ID        string
+	Name      string
+	City      string
+	Country   string
+	Alias     string
+	Regions   string
+	Latitude  string
+	Longitude string
+	Province  string
+	Timezone  string
+	Unlocs    string
+	Code      string
+}
+
+// DefaultCSVColumns returns the headers named after the JSON members of MaritimePort,
+// with the coordinates split into a 'lat' and a 'lon' column.
+func DefaultCSVColumns() CSVColumns {
+	return CSVColumns{
+		ID:        "id",
+		Name:      "name",
+		City:      "city",
+		Country:   "country",
+		Alias:     "alias",
+		Regions:   "regions",
+		Latitude:  "lat",
+		Longitude: "lon",
+		Province:  "province",
+		Timezone:  "timezone",
+		Unlocs:    "unlocs",
+		Code:      "code",
+	}
+}
+
+// RowError represents an error in a single row of CSV data. Rows are numbered from 1,
+// starting with the header row, the same way spreadsheets number them.
+type RowError struct {
+	Row int
+	Err error
+}
+
+// Error returns the message of the underlying error prefixed by the row number.
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// CSVLoader is a service responsible for loading csv data.
+type CSVLoader struct {
+	Repository PortsStore
+	Columns    CSVColumns
+	// Comma is the field delimiter.
+	Comma rune
+	// ListDelimiter separates the values of multi-valued columns like alias, regions and unlocs.
+	ListDelimiter string
+}
+
+// NewCSVLoader is a constructor function for CSVLoader. It expects comma-separated values
+// with DefaultCSVColumns headers and multiple values of a column separated by '|'.
+func NewCSVLoader(repository PortsStore) *CSVLoader {
+	return &CSVLoader{
+		Repository:    repository,
+		Columns:       DefaultCSVColumns(),
+		Comma:         ',',
+		ListDelimiter: "|",
+	}
+}
+
+// LoadCSVFile reads a CSV file and delegates loading to Load method.
+func (l *CSVLoader) LoadCSVFile(ctx context.Context, csvFilePath string) error {
+	dataFilePath, errPath := filepath.Abs(csvFilePath)
+	portsData, errFile := os.Open(dataFilePath)
+
+	if err := errors.Join(errPath, errFile); err != nil {
+		return pkgErrors.Wrapf(err, "cannot access ports data from file %s", dataFilePath)
+	}
+
+	defer portsData.Close()
+
+	if _, err := l.Load(ctx, portsData); err != nil {
+		return pkgErrors.Wrapf(err, "cannot load ports from file: %s", dataFilePath)
+	}
+
+	return nil
+}
+
+// Load stores CSV data row by row via PortsStore and returns the number of loaded ports.
+// The header row is mapped to port fields according to l.Columns, ignoring unmapped
+// columns. Loading stops with a RowError at the first malformed row or invalid port.
+func (l *CSVLoader) Load(ctx context.Context, r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = l.Comma
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return 0, csvError(err)
+	}
+
+	cols, err := l.columnIndexes(header)
+	if err != nil {
+		return 0, &RowError{Row: 1, Err: err}
+	}
+
+	loaded := 0
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return loaded, pkgErrors.WithStack(err)
+		}
+
+		record, errRead := cr.Read()
+		if errors.Is(errRead, io.EOF) {
+			return loaded, nil
+		}
+
+		if errRead != nil {
+			return loaded, csvError(errRead)
+		}
+
+		row, _ := cr.FieldPos(0)
+
+		p, errPort := l.port(cols, record)
+		if errPort == nil {
+			errPort = p.Validate()
+		}
+
+		if errPort != nil {
+			return loaded, &RowError{Row: row, Err: pkgErrors.Wrapf(errPort, "invalid port entry with ID '%s'", p.ID)}
+		}
+
+		if _, _, err = l.Repository.UpsertPort(ctx, p, Precondition{}); err != nil {
+			return loaded, &RowError{Row: row, Err: pkgErrors.WithStack(err)}
+		}
+
+		loaded++
+	}
+}
+
+// csvColumns holds the indexes of the mapped columns in a row, -1 for missing ones.
+type csvColumns struct {
+	id, name, city, country, alias, regions, lat, lon, province, timezone, unlocs, code int
+}
+
+// columnIndexes maps the headers of the data to the indexes of the configured columns.
+func (l *CSVLoader) columnIndexes(header []string) (*csvColumns, error) {
+	indexes := make(map[string]int, len(header))
+
+	for i, h := range header {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+
+		key := strings.ToLower(strings.TrimSpace(h))
+		if _, ok := indexes[key]; !ok {
+			indexes[key] = i
+		}
+	}
+
+	index := func(column string) int {
+		if i, ok := indexes[strings.ToLower(strings.TrimSpace(column))]; ok && column != "" {
+			return i
+		}
+
+		return -1
+	}
+
+	cols := &csvColumns{
+		id:       index(l.Columns.ID),
+		name:     index(l.Columns.Name),
+		city:     index(l.Columns.City),
+		country:  index(l.Columns.Country),
+		alias:    index(l.Columns.Alias),
+		regions:  index(l.Columns.Regions),
+		lat:      index(l.Columns.Latitude),
+		lon:      index(l.Columns.Longitude),
+		province: index(l.Columns.Province),
+		timezone: index(l.Columns.Timezone),
+		unlocs:   index(l.Columns.Unlocs),
+		code:     index(l.Columns.Code),
+	}
+
+	if cols.id < 0 {
+		return nil, NewError(ErrValidation, "ports data has no '%s' column", l.Columns.ID)
+	}
+
+	if (cols.lat < 0) != (cols.lon < 0) {
+		return nil, NewError(
+			ErrValidation, "ports data must have either both '%s' and '%s' columns or none of them",
+			l.Columns.Latitude, l.Columns.Longitude)
+	}
+
+	return cols, nil
+}
+
+// port maps a row to a MaritimePort.
+func (l *CSVLoader) port(cols *csvColumns, record []string) (*MaritimePort, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	p := &MaritimePort{
+		ID:       field(cols.id),
+		Name:     field(cols.name),
+		City:     field(cols.city),
+		Country:  field(cols.country),
+		Alias:    l.list(field(cols.alias)),
+		Regions:  l.list(field(cols.regions)),
+		Province: field(cols.province),
+		Timezone: field(cols.timezone),
+		Unlocs:   l.list(field(cols.unlocs)),
+		Code:     field(cols.code),
+	}
+
+	lat, lon := field(cols.lat), field(cols.lon)
+	if lat == "" && lon == "" {
+		return p, nil
+	}
+
+	latitude, errLat := strconv.ParseFloat(lat, 64)
+	longitude, errLon := strconv.ParseFloat(lon, 64)
+
+	if errLat != nil || errLon != nil {
+		return p, NewViolationError(
+			"coordinates", "coordinates must be a pair of numbers, got latitude '%s' and longitude '%s'", lat, lon)
+	}
+
+	p.Coordinates = NewGeoPoint(latitude, longitude)
+
+	return p, nil
+}
+
+// list splits the values of a multi-valued column.
+func (l *CSVLoader) list(s string) []string {
+	values := make([]string, 0)
+
+	for _, v := range strings.Split(s, l.ListDelimiter) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// csvError numbers the rows of malformed CSV data and marks it as an ErrValidation error.
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &RowError{Row: parseErr.Line, Err: WithKind(ErrValidation, parseErr.Err)}
+	}
+
+	if errors.Is(err, io.EOF) {
+		return NewError(ErrValidation, "ports data has no header row")
+	}
+
+	return pkgErrors.WithStack(err)
+}
@@ -0,0 +1,133 @@
+package portsmanaging_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+)
+
+func TestCSVLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should load CSV data with the default columns", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewCSVLoader(portsStore)
+
+		loaded, err := loader.Load(context.Background(), strings.NewReader(
+			"\ufeffid,name,city,country,alias,regions,lat,lon,province,timezone,unlocs,code,notes\n"+
+				"AEAJM,Ajman,Ajman,United Arab Emirates,,,25.4052165,55.5136433,Ajman,Asia/Dubai,AEAJM,52000,checked\n"+
+				`AEDXB,Dubai,Dubai,United Arab Emirates,"Dubai Port | Port Rashid",,,,,Asia/Dubai,AEDXB|AEJEA,`+"\n",
+		))
+		require.NoError(t, err)
+		assert.Equal(t, 2, loaded)
+
+		p, err := portsStore.GetPortByID(context.Background(), "AEAJM")
+		require.NoError(t, err)
+		assert.Equal(t, &portsmanaging.MaritimePort{
+			Version:     1,
+			ID:          "AEAJM",
+			Name:        "Ajman",
+			City:        "Ajman",
+			Country:     "United Arab Emirates",
+			Alias:       []string{},
+			Regions:     []string{},
+			Coordinates: portsmanaging.NewGeoPoint(25.4052165, 55.5136433),
+			Province:    "Ajman",
+			Timezone:    "Asia/Dubai",
+			Unlocs:      []string{"AEAJM"},
+			Code:        "52000",
+		}, p)
+
+		p, err = portsStore.GetPortByID(context.Background(), "AEDXB")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Dubai Port", "Port Rashid"}, p.Alias)
+		assert.Equal(t, []string{"AEDXB", "AEJEA"}, p.Unlocs)
+		assert.Nil(t, p.Coordinates)
+	})
+
+	t.Run("should load CSV data with configured columns and delimiters", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewCSVLoader(portsStore)
+		loader.Comma = ';'
+		loader.ListDelimiter = ","
+		loader.Columns = portsmanaging.CSVColumns{
+			ID:        "UN/LOCODE",
+			Name:      "Port Name",
+			Country:   "Country",
+			Latitude:  "Latitude",
+			Longitude: "Longitude",
+			Unlocs:    "Other Codes",
+		}
+
+		loaded, err := loader.Load(context.Background(), strings.NewReader(
+			"Port Name;un/locode;Country;Latitude;Longitude;Other codes\n"+
+				"Varna;BGVAR;Bulgaria;43.2;27.91;BGVAR, BGVRN\n",
+		))
+		require.NoError(t, err)
+		assert.Equal(t, 1, loaded)
+
+		p, err := portsStore.GetPortByID(context.Background(), "BGVAR")
+		require.NoError(t, err)
+		assert.Equal(t, "Varna", p.Name)
+		assert.Equal(t, portsmanaging.NewGeoPoint(43.2, 27.91), p.Coordinates)
+		assert.Equal(t, []string{"BGVAR", "BGVRN"}, p.Unlocs)
+	})
+
+	t.Run("should report the row of an invalid port", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewCSVLoader(portsStore)
+
+		loaded, err := loader.Load(context.Background(), strings.NewReader(
+			"id,name,country,lat,lon\n"+
+				"BGVAR,Varna,Bulgaria,43.2,27.91\n"+
+				"\"BGBOJ\",\"Burgas\nBay\",Bulgaria,42.5,27.48\n"+
+				"BGNES,Nesebar,,42.66,27.73\n"+
+				"BGSOZ,Sozopol,Bulgaria,42.42,27.69\n",
+		))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Equal(t, 2, loaded)
+		assert.EqualError(t, err, "row 5: invalid port entry with ID 'BGNES': country is required")
+
+		var rowErr *portsmanaging.RowError
+		require.ErrorAs(t, err, &rowErr)
+		assert.Equal(t, 5, rowErr.Row)
+		assert.Equal(t, []portsmanaging.Violation{{Field: "country", Message: "country is required"}},
+			portsmanaging.Violations(err))
+	})
+
+	t.Run("should report the row of malformed data", func(t *testing.T) {
+		loader := portsmanaging.NewCSVLoader(memory.NewPortsRepository())
+
+		_, err := loader.Load(context.Background(), strings.NewReader(
+			"id,name,country,lat,lon\n"+
+				"BGVAR,Varna,Bulgaria,north,27.91\n",
+		))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.EqualError(t, err, "row 2: invalid port entry with ID 'BGVAR': "+
+			"coordinates must be a pair of numbers, got latitude 'north' and longitude '27.91'")
+
+		_, err = loader.Load(context.Background(), strings.NewReader(
+			"id,name,country\n"+
+				"BGVAR,\"Varna,Bulgaria\n",
+		))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+
+		var rowErr *portsmanaging.RowError
+		require.ErrorAs(t, err, &rowErr)
+		assert.Equal(t, 2, rowErr.Row)
+	})
+
+	t.Run("should reject data without an ID column", func(t *testing.T) {
+		loader := portsmanaging.NewCSVLoader(memory.NewPortsRepository())
+
+		_, err := loader.Load(context.Background(), strings.NewReader("name,country\nVarna,Bulgaria\n"))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.EqualError(t, err, "row 1: ports data has no 'id' column")
+	})
+}
@@ -0,0 +1,117 @@
+package portsmanaging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of domain errors. Every Error wraps one of them, so that callers can tell
+// them apart with errors.Is regardless of any context added along the way.
+var (
+	// ErrNotFound means that the requested port does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrValidation means that a query, a port or a patch is invalid.
+	ErrValidation = errors.New("validation failed")
+	// ErrConflict means that a request cannot be applied to the current state of a port.
+	ErrConflict = errors.New("conflict")
+	// ErrCorrupt means that stored port data cannot be read back.
+	ErrCorrupt = errors.New("corrupt data")
+	// ErrPreconditionFailed means that a conditional write does not match the current version of a port.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Violation represents a constraint violated by the value of a single field.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error represents a domain error of a given kind. Validation errors additionally
+// list the violations of individual fields, when known.
+type Error struct {
+	Kind       error
+	Err        error
+	Violations []Violation
+}
+
+// NewError returns an Error of the given kind with a formatted message.
+func NewError(kind error, format string, args ...any) error {
+	return &Error{
+		Kind: kind,
+		Err:  fmt.Errorf(format, args...),
+	}
+}
+
+// NewViolationError returns an ErrValidation error for a single invalid field.
+func NewViolationError(field string, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+
+	return &Error{
+		Kind:       ErrValidation,
+		Err:        errors.New(msg),
+		Violations: []Violation{{Field: field, Message: msg}},
+	}
+}
+
+// NewValidationError returns an ErrValidation error listing all violations or nil
+// if there are none.
+func NewValidationError(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, 0, len(violations))
+	for _, v := range violations {
+		msgs = append(msgs, v.Message)
+	}
+
+	return &Error{
+		Kind:       ErrValidation,
+		Err:        errors.New(strings.Join(msgs, "; ")),
+		Violations: violations,
+	}
+}
+
+// Violations returns the field violations carried by err, if any.
+func Violations(err error) []Violation {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Violations
+	}
+
+	return nil
+}
+
+// NotFoundError returns an ErrNotFound error for the port with the given ID.
+func NotFoundError(id string) error {
+	return NewError(ErrNotFound, "port entry with ID '%s' not found", id)
+}
+
+// WithKind marks err as an Error of the given kind, unless it already is an Error
+// in which case its original kind is kept. It returns nil if err is nil.
+func WithKind(kind error, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	return &Error{
+		Kind: kind,
+		Err:  err,
+	}
+}
+
+// Error returns the message of the underlying error.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns both the kind and the underlying error.
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
@@ -0,0 +1,50 @@
+package portsmanaging_test
+
+import (
+	"errors"
+	"testing"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should match the kind of an error wrapped with context", func(t *testing.T) {
+		err := pkgErrors.Wrap(portsmanaging.NotFoundError("AEDXB"), "could not get port")
+
+		assert.ErrorIs(t, err, portsmanaging.ErrNotFound)
+		assert.NotErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.EqualError(t, err, "could not get port: port entry with ID 'AEDXB' not found")
+	})
+
+	t.Run("should keep the original kind of an error marked with another kind", func(t *testing.T) {
+		conflict := portsmanaging.NewError(portsmanaging.ErrConflict, "test failed")
+		err := portsmanaging.WithKind(portsmanaging.ErrValidation, pkgErrors.Wrap(conflict, "patch failed"))
+
+		assert.ErrorIs(t, err, portsmanaging.ErrConflict)
+		assert.NotErrorIs(t, err, portsmanaging.ErrValidation)
+	})
+
+	t.Run("should mark plain errors with the given kind", func(t *testing.T) {
+		cause := errors.New("invalid JSON")
+		err := portsmanaging.WithKind(portsmanaging.ErrValidation, cause)
+
+		assert.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.ErrorIs(t, err, cause)
+		assert.NoError(t, portsmanaging.WithKind(portsmanaging.ErrValidation, nil))
+	})
+
+	t.Run("should expose the field violations of a validation error wrapped with context", func(t *testing.T) {
+		err := pkgErrors.Wrap(portsmanaging.NewViolationError("k", "k must be between %d and %d", 1, 100), "query failed")
+
+		assert.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Equal(t, []portsmanaging.Violation{
+			{Field: "k", Message: "k must be between 1 and 100"},
+		}, portsmanaging.Violations(err))
+		assert.Empty(t, portsmanaging.Violations(portsmanaging.NotFoundError("AEDXB")))
+	})
+}
@@ -0,0 +1,151 @@
+package portsmanaging
+
+import "math"
+
+const (
+	// EarthRadiusKm is the mean Earth radius used for great-circle distances.
+	EarthRadiusKm = 6371.0088
+	// MaxNearestK is the maximum number of ports returned by a nearest ports query.
+	MaxNearestK = 100
+	// DefaultNearestK is the number of ports returned by a nearest ports query by default.
+	DefaultNearestK = 10
+)
+
+// PortDistance represents a port together with its great-circle distance to a queried location.
+type PortDistance struct {
+	Port       *MaritimePort `json:"port"`
+	DistanceKm float64       `json:"distance_km"`
+}
+
+// NearestQuery represents a query for the K ports nearest to a location,
+// optionally restricted to those within MaxKm kilometers (0 means no restriction).
+type NearestQuery struct {
+	Lat   float64
+	Lon   float64
+	K     int
+	MaxKm float64
+}
+
+// Validate checks the consistency of the query and applies the defaults.
+func (q *NearestQuery) Validate() error {
+	if err := validateLatLon(q.Lat, q.Lon); err != nil {
+		return err
+	}
+
+	if q.K == 0 {
+		q.K = DefaultNearestK
+	}
+
+	if q.K < 0 || q.K > MaxNearestK {
+		return NewViolationError("k", "k must be between 1 and %d", MaxNearestK)
+	}
+
+	if q.MaxKm < 0 {
+		return NewViolationError("max_km", "max_km must not be negative")
+	}
+
+	return nil
+}
+
+// BoundingBox represents a geographic viewport in degrees. A box with MinLon greater
+// than MaxLon crosses the antimeridian, i.e. it spans from MinLon eastwards to MaxLon.
+type BoundingBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// Validate checks the consistency of the bounding box.
+func (b BoundingBox) Validate() error {
+	if err := validateLatLon(b.MinLat, b.MinLon); err != nil {
+		return err
+	}
+
+	if err := validateLatLon(b.MaxLat, b.MaxLon); err != nil {
+		return err
+	}
+
+	if b.MinLat > b.MaxLat {
+		return NewViolationError(
+			"bbox", "minimum latitude %v is greater than maximum latitude %v", b.MinLat, b.MaxLat)
+	}
+
+	return nil
+}
+
+// CrossesAntimeridian reports whether the bounding box spans over the 180th meridian.
+func (b BoundingBox) CrossesAntimeridian() bool {
+	return b.MinLon > b.MaxLon
+}
+
+// Contains reports whether the location lies within the bounding box.
+func (b BoundingBox) Contains(lat float64, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+
+	if b.CrossesAntimeridian() {
+		return lon >= b.MinLon || lon <= b.MaxLon
+	}
+
+	return lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// RadiusQuery represents a query for all ports within RadiusKm kilometers of a location.
+type RadiusQuery struct {
+	Lat      float64
+	Lon      float64
+	RadiusKm float64
+}
+
+// Validate checks the consistency of the query.
+func (q RadiusQuery) Validate() error {
+	if err := validateLatLon(q.Lat, q.Lon); err != nil {
+		return err
+	}
+
+	if q.RadiusKm <= 0 || math.IsNaN(q.RadiusKm) {
+		return NewViolationError("radius_km", "radius_km must be positive")
+	}
+
+	return nil
+}
+
+// Location returns the latitude and longitude of the port. The boolean result
+// is false if the port has no valid coordinates.
+func (p *MaritimePort) Location() (lat float64, lon float64, ok bool) {
+	if p.Coordinates == nil {
+		return 0, 0, false
+	}
+
+	return p.Coordinates.Lat, p.Coordinates.Lon, p.Coordinates.Validate() == nil
+}
+
+// HaversineKm returns the great-circle distance in kilometers between two locations.
+func HaversineKm(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	phi1, phi2 := toRadians(lat1), toRadians(lat2)
+	dPhi := phi2 - phi1
+	dLambda := toRadians(lon2 - lon1)
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func validateLatLon(lat float64, lon float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return NewViolationError("lat", "latitude must be between -90 and 90, got %v", lat)
+	}
+
+	if math.IsNaN(lon) || lon < -180 || lon > 180 {
+		return NewViolationError("lon", "longitude must be between -180 and 180, got %v", lon)
+	}
+
+	return nil
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,89 @@
+package portsmanaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// GeoPoint represents a location given by its latitude and longitude in degrees.
+//
+// A GeoPoint is encoded in JSON as a GeoJSON style [longitude, latitude] array, which is
+// the form ports have always been stored and served in. Decoding additionally accepts
+// a {"lat": ..., "lon": ...} object.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// NewGeoPoint is a constructor function for GeoPoint.
+func NewGeoPoint(lat float64, lon float64) *GeoPoint {
+	return &GeoPoint{Lat: lat, Lon: lon}
+}
+
+// Validate checks that the latitude and longitude are within their ranges.
+func (g GeoPoint) Validate() error {
+	return validateLatLon(g.Lat, g.Lon)
+}
+
+// DistanceKm returns the great-circle distance in kilometers to another point.
+func (g GeoPoint) DistanceKm(to GeoPoint) float64 {
+	return HaversineKm(g.Lat, g.Lon, to.Lat, to.Lon)
+}
+
+// BearingDeg returns the initial bearing in degrees clockwise from true north, in the
+// range [0, 360), of the great-circle path to another point.
+func (g GeoPoint) BearingDeg(to GeoPoint) float64 {
+	phi1, phi2 := toRadians(g.Lat), toRadians(to.Lat)
+	dLambda := toRadians(to.Lon - g.Lon)
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// MarshalJSON encodes the point as a [longitude, latitude] array.
+func (g GeoPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]float64{g.Lon, g.Lat})
+}
+
+// UnmarshalJSON decodes the point from either a [longitude, latitude] array
+// or a {"lat": ..., "lon": ...} object.
+func (g *GeoPoint) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		var obj struct {
+			Lat *float64 `json:"lat"`
+			Lon *float64 `json:"lon"`
+		}
+
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+
+		if obj.Lat == nil || obj.Lon == nil {
+			return fmt.Errorf("coordinates object must have both 'lat' and 'lon' members")
+		}
+
+		g.Lat, g.Lon = *obj.Lat, *obj.Lon
+
+		return nil
+	}
+
+	var pair []float64
+
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return fmt.Errorf("coordinates must be a [longitude, latitude] array or a {\"lat\", \"lon\"} object")
+	}
+
+	if len(pair) != 2 {
+		return fmt.Errorf("coordinates must be a [longitude, latitude] pair, got %d values", len(pair))
+	}
+
+	g.Lon, g.Lat = pair[0], pair[1]
+
+	return nil
+}
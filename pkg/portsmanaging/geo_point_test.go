@@ -0,0 +1,67 @@
+package portsmanaging_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+func TestGeoPointJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should encode a point as a [longitude, latitude] array", func(t *testing.T) {
+		b, err := json.Marshal(portsmanaging.NewGeoPoint(25.25, 55.27))
+		require.NoError(t, err)
+		assert.JSONEq(t, `[55.27, 25.25]`, string(b))
+	})
+
+	t.Run("should decode a point from both the array and the object form", func(t *testing.T) {
+		var fromArray, fromObject portsmanaging.GeoPoint
+
+		require.NoError(t, json.Unmarshal([]byte(`[55.27, 25.25]`), &fromArray))
+		require.NoError(t, json.Unmarshal([]byte(`{"lat": 25.25, "lon": 55.27}`), &fromObject))
+		assert.Equal(t, *portsmanaging.NewGeoPoint(25.25, 55.27), fromArray)
+		assert.Equal(t, fromArray, fromObject)
+	})
+
+	t.Run("should decode missing coordinates as a nil point", func(t *testing.T) {
+		var p portsmanaging.MaritimePort
+
+		require.NoError(t, json.Unmarshal([]byte(`{"id": "AEDXB", "coordinates": null}`), &p))
+		assert.Nil(t, p.Coordinates)
+	})
+
+	t.Run("should reject malformed points", func(t *testing.T) {
+		for _, data := range []string{`[55.27, 25.25, 0]`, `[55.27]`, `{"lat": 25.25}`, `"55.27,25.25"`} {
+			var g portsmanaging.GeoPoint
+
+			assert.Error(t, json.Unmarshal([]byte(data), &g), data)
+		}
+	})
+}
+
+func TestGeoPointGeometry(t *testing.T) {
+	t.Parallel()
+
+	origin := portsmanaging.NewGeoPoint(0, 0)
+
+	t.Run("should return the great-circle distance to another point", func(t *testing.T) {
+		assert.InDelta(t, 111.195, origin.DistanceKm(*portsmanaging.NewGeoPoint(0, 1)), 0.001)
+		assert.Zero(t, origin.DistanceKm(*origin))
+	})
+
+	t.Run("should return the initial bearing to another point", func(t *testing.T) {
+		assert.InDelta(t, 0, origin.BearingDeg(*portsmanaging.NewGeoPoint(1, 0)), 1e-9)
+		assert.InDelta(t, 90, origin.BearingDeg(*portsmanaging.NewGeoPoint(0, 1)), 1e-9)
+		assert.InDelta(t, 180, origin.BearingDeg(*portsmanaging.NewGeoPoint(-1, 0)), 1e-9)
+		assert.InDelta(t, 270, origin.BearingDeg(*portsmanaging.NewGeoPoint(0, -1)), 1e-9)
+
+		// Varna to Istanbul heads roughly south-south-east.
+		varna, istanbul := portsmanaging.NewGeoPoint(43.2, 27.91), portsmanaging.NewGeoPoint(41.01, 28.98)
+		assert.InDelta(t, 159.6, varna.BearingDeg(*istanbul), 1)
+	})
+}
@@ -0,0 +1,128 @@
+package portsmanaging
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// fixturePort represents a MaritimePort the way it is stored in the ports fixtures,
+// i.e. keyed by its ID rather than containing it.
+type fixturePort struct {
+	Version     uint64    `json:"-"`
+	ID          string    `json:"-"`
+	Name        string    `json:"name"`
+	City        string    `json:"city"`
+	Country     string    `json:"country"`
+	Alias       []string  `json:"alias"`
+	Regions     []string  `json:"regions"`
+	Coordinates *GeoPoint `json:"coordinates"`
+	Province    string    `json:"province"`
+	Timezone    string    `json:"timezone"`
+	Unlocs      []string  `json:"unlocs"`
+	Code        string    `json:"code,omitempty"`
+}
+
+// JSONExporter is a service responsible for exporting json data.
+type JSONExporter struct {
+	Repository PortsStore
+	// PageSize is the number of ports fetched from the store at once.
+	PageSize int
+}
+
+// NewJSONExporter is a constructor function for JSONExporter.
+func NewJSONExporter(repository PortsStore) *JSONExporter {
+	return &JSONExporter{
+		Repository: repository,
+		PageSize:   MaxListLimit,
+	}
+}
+
+// Export writes all ports ordered by ID to w as a JSON object of ports keyed by their IDs,
+// in the same shape as the ports fixtures, and returns the number of exported ports.
+func (e *JSONExporter) Export(ctx context.Context, w io.Writer) (int, error) {
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.WriteString("{"); err != nil {
+		return 0, pkgErrors.WithStack(err)
+	}
+
+	exported, err := e.each(ctx, func(i int, p *MaritimePort) error {
+		key, errKey := json.Marshal(p.ID)
+		value, errValue := json.MarshalIndent((*fixturePort)(p), "  ", "  ")
+
+		if err := errors.Join(errKey, errValue); err != nil {
+			return pkgErrors.Wrapf(err, "cannot encode port entry with ID '%s'", p.ID)
+		}
+
+		sep := ",\n  "
+		if i == 0 {
+			sep = "\n  "
+		}
+
+		_, errSep := bw.WriteString(sep)
+		_, errKey = bw.Write(key)
+		_, errColon := bw.WriteString(": ")
+		_, errValue = bw.Write(value)
+
+		return pkgErrors.WithStack(errors.Join(errSep, errKey, errColon, errValue))
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	if _, err = bw.WriteString("\n}\n"); err != nil {
+		return exported, pkgErrors.WithStack(err)
+	}
+
+	return exported, pkgErrors.WithStack(bw.Flush())
+}
+
+// ExportNDJSON writes all ports ordered by ID to w as newline-delimited JSON, one port
+// including its ID per line, and returns the number of exported ports.
+func (e *JSONExporter) ExportNDJSON(ctx context.Context, w io.Writer) (int, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	exported, err := e.each(ctx, func(_ int, p *MaritimePort) error {
+		return pkgErrors.Wrapf(enc.Encode(p), "cannot encode port entry with ID '%s'", p.ID)
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	return exported, pkgErrors.WithStack(bw.Flush())
+}
+
+// each calls fn for all ports ordered by ID, fetching them page by page so that they
+// are never all held at once. It stops as soon as fn fails and returns the number of
+// ports fn succeeded for.
+func (e *JSONExporter) each(ctx context.Context, fn func(i int, p *MaritimePort) error) (int, error) {
+	opts := ListOptions{Limit: e.PageSize, SortBy: SortByID}
+	done := 0
+
+	for {
+		page, err := e.Repository.ListPorts(ctx, opts)
+		if err != nil {
+			return done, err
+		}
+
+		for _, p := range page.Ports {
+			if err = fn(done, p); err != nil {
+				return done, err
+			}
+
+			done++
+		}
+
+		if page.NextCursor == "" {
+			return done, nil
+		}
+
+		opts.Cursor = page.NextCursor
+	}
+}
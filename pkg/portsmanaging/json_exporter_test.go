@@ -0,0 +1,82 @@
+package portsmanaging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+)
+
+func TestJSONExporter(t *testing.T) {
+	t.Parallel()
+
+	portsStore := memory.NewPortsRepository()
+	_, err := portsmanaging.NewJSONLoader(portsStore).LoadJSONFile(
+		context.Background(), "../../testdata/test_data_ports.json")
+	require.NoError(t, err)
+
+	exporter := portsmanaging.NewJSONExporter(portsStore)
+	exporter.PageSize = 2
+
+	t.Run("should export JSON data in the shape of the fixtures", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		exported, err := exporter.Export(context.Background(), &buf)
+		require.NoError(t, err)
+		assert.Equal(t, 3, exported)
+
+		var entries map[string]map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+		assert.Len(t, entries, 3)
+		assert.NotContains(t, entries["AEAJM"], "id")
+		assert.Equal(t, "Ajman", entries["AEAJM"]["name"])
+
+		reloaded := memory.NewPortsRepository()
+		report, err := portsmanaging.NewJSONLoader(reloaded).Load(context.Background(), &buf)
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Loaded)
+		assertSamePorts(t, portsStore, reloaded)
+	})
+
+	t.Run("should export NDJSON data", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		exported, err := exporter.ExportNDJSON(context.Background(), &buf)
+		require.NoError(t, err)
+		assert.Equal(t, 3, exported)
+		assert.Equal(t, 3, bytes.Count(buf.Bytes(), []byte("\n")))
+
+		reloaded := memory.NewPortsRepository()
+		report, err := portsmanaging.NewJSONLoader(reloaded).LoadNDJSON(context.Background(), &buf)
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Loaded)
+		assertSamePorts(t, portsStore, reloaded)
+	})
+
+	t.Run("should export an empty store", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		exported, err := portsmanaging.NewJSONExporter(memory.NewPortsRepository()).Export(context.Background(), &buf)
+		require.NoError(t, err)
+		assert.Zero(t, exported)
+		assert.JSONEq(t, `{}`, buf.String())
+	})
+}
+
+func assertSamePorts(t *testing.T, expected portsmanaging.PortsStore, actual portsmanaging.PortsStore) {
+	t.Helper()
+
+	expectedPorts, err := expected.ListPorts(context.Background(), portsmanaging.ListOptions{})
+	require.NoError(t, err)
+
+	actualPorts, err := actual.ListPorts(context.Background(), portsmanaging.ListOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedPorts, actualPorts)
+}
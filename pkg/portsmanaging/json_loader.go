@@ -1,19 +1,51 @@
 package portsmanaging
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	pkgErrors "github.com/pkg/errors"
 )
 
+// DataFormat represents the format of imported or exported ports data.
+type DataFormat int
+
+const (
+	// FixturesJSON is a JSON object of ports keyed by their IDs, like the ports fixtures.
+	FixturesJSON DataFormat = iota
+	// NDJSON is newline-delimited JSON with a single port including its ID per line.
+	NDJSON
+)
+
 // JSONLoader is a service responsible for loading json data.
 type JSONLoader struct {
 	Repository PortsStore
+	// Lenient makes loading skip malformed and invalid ports, which are reported as rejected
+	// records, instead of failing at the first one.
+	Lenient bool
+	// ErrorBudget is the number of records which can be rejected in lenient mode before
+	// loading fails. Zero means no limit.
+	ErrorBudget int
+	// ReportPath is the file the report of LoadJSONFile is written to, unless empty.
+	ReportPath string
+	// Workers is the number of goroutines decoding, validating and storing ports concurrently,
+	// while a single goroutine reads the data. Ports are loaded sequentially unless it is
+	// greater than one.
+	Workers int
+	// QueueSize is the number of records queued per worker, 64 by default.
+	QueueSize int
+	// Progress is called with the progress of loading every ProgressInterval records, 1000
+	// by default, and once loading ends, unless nil.
+	Progress         func(LoadProgress)
+	ProgressInterval int
 }
 
 // NewJSONLoader is a constructor function for JSONLoader.
@@ -23,56 +55,187 @@ func NewJSONLoader(repository PortsStore) *JSONLoader {
 	}
 }
 
-// LoadJSONFile reads a JSON file and delegates loading to Load method.
-func (l *JSONLoader) LoadJSONFile(jsonFilePath string) error {
+// LoadJSONFile reads a JSON file and delegates loading to Load method. The returned report
+// is also written to ReportPath, if set, even if loading fails.
+func (l *JSONLoader) LoadJSONFile(ctx context.Context, jsonFilePath string) (*LoadReport, error) {
 	dataFilePath, errPath := filepath.Abs(jsonFilePath)
 	portsFixtures, errFile := os.Open(dataFilePath)
 
 	if err := errors.Join(errPath, errFile); err != nil {
-		return pkgErrors.Wrapf(err, "cannot access ports data from file %s", dataFilePath)
+		return NewLoadReport(), pkgErrors.Wrapf(err, "cannot access ports data from file %s", dataFilePath)
 	}
 
-	if err := l.Load(portsFixtures); err != nil {
-		return pkgErrors.Wrapf(err, "cannot load ports from file: %s", dataFilePath)
+	defer portsFixtures.Close()
+
+	report, err := l.Load(ctx, portsFixtures)
+	if err != nil {
+		err = pkgErrors.Wrapf(err, "cannot load ports from file: %s", dataFilePath)
 	}
 
-	return nil
-}
+	if l.ReportPath != "" {
+		err = errors.Join(err, report.WriteFile(l.ReportPath))
+	}
 
-// Load stores JSON data in chunks via PortsStore.
-func (l *JSONLoader) Load(r io.Reader) error {
-	dec := json.NewDecoder(r)
+	return report, err
+}
 
-	var (
-		token json.Token
-		err   error
-	)
+// Load stores a JSON object of ports keyed by their IDs, like the ports fixtures, via
+// PortsStore and returns a report of the loaded ports. In lenient mode, invalid ports are
+// skipped and reported instead, unless the data itself is not well-formed JSON.
+func (l *JSONLoader) Load(ctx context.Context, r io.Reader) (*LoadReport, error) {
+	dr := &dataReader{r: r}
+	dec := json.NewDecoder(dr)
 
-	_, err = dec.Token()
+	token, err := dec.Token()
 	if err != nil {
-		return pkgErrors.WithStack(err)
+		return NewLoadReport(), dr.decodeError(err)
 	}
 
-	for dec.More() {
-		token, err = dec.Token()
+	if token != json.Delim('{') {
+		return NewLoadReport(), NewError(ErrValidation, "ports data must be a JSON object keyed by port IDs")
+	}
+
+	return l.load(ctx, dr, func() (*loadRecord, error) {
+		if !dec.More() {
+			return nil, io.EOF
+		}
+
+		key, err := dec.Token()
 		if err != nil {
-			return pkgErrors.WithStack(err)
+			return nil, dr.decodeError(err)
 		}
 
-		var p MaritimePort
+		offset := dec.InputOffset()
 
-		err = dec.Decode(&p)
-		if err != nil {
-			return pkgErrors.WithStack(err)
+		var raw json.RawMessage
+
+		if err = dec.Decode(&raw); err != nil {
+			return nil, dr.decodeError(err)
 		}
 
-		p.ID = fmt.Sprint(token)
+		id := fmt.Sprint(key)
 
-		_, _, err = l.Repository.UpsertPort(&p)
-		if err != nil {
-			return pkgErrors.WithStack(err)
+		return &loadRecord{
+			key:      id,
+			raw:      raw,
+			rejected: RejectedRecord{ID: id, Offset: offset},
+		}, nil
+	})
+}
+
+// LoadNDJSON stores newline-delimited JSON data with a port including its ID per line via
+// PortsStore and returns a report of the loaded ports. Blank lines are ignored, while in
+// lenient mode invalid lines are skipped and reported instead.
+func (l *JSONLoader) LoadNDJSON(ctx context.Context, r io.Reader) (*LoadReport, error) {
+	dr := &dataReader{r: r}
+	br := bufio.NewReader(dr)
+
+	var (
+		offset int64
+		line   int
+	)
+
+	return l.load(ctx, dr, func() (*loadRecord, error) {
+		for {
+			data, err := br.ReadBytes('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, pkgErrors.WithStack(err)
+			}
+
+			if len(data) == 0 {
+				return nil, io.EOF
+			}
+
+			line++
+			rec := &loadRecord{rejected: RejectedRecord{Offset: offset, Line: line}}
+			offset += int64(len(data))
+
+			if rec.raw = bytes.TrimSpace(data); len(rec.raw) > 0 {
+				return rec, nil
+			}
+		}
+	})
+}
+
+// process decodes, validates and stores the port of a single record, setting its outcome.
+func (l *JSONLoader) process(ctx context.Context, rec *loadRecord) {
+	p, err := decodePort(rec.raw)
+	if p != nil {
+		if rec.key != "" {
+			p.ID = rec.key
 		}
+
+		rec.rejected.ID = p.ID
+	}
+
+	if err == nil {
+		err = l.store(ctx, p)
+	}
+
+	rec.err = err
+}
+
+// store validates and upserts a single loaded port.
+func (l *JSONLoader) store(ctx context.Context, p *MaritimePort) error {
+	if err := p.Validate(); err != nil {
+		return pkgErrors.Wrapf(err, "invalid port entry with ID '%s'", p.ID)
+	}
+
+	if _, _, err := l.Repository.UpsertPort(ctx, p, Precondition{}); err != nil {
+		return pkgErrors.WithStack(err)
 	}
 
 	return nil
 }
+
+// decodePort decodes a single port, marking malformed data as an ErrValidation error. The
+// port decoded so far is returned along with the error, if the data is well-formed JSON.
+func decodePort(data []byte) (*MaritimePort, error) {
+	var p MaritimePort
+
+	if err := json.Unmarshal(data, &p); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, pkgErrors.WithStack(WithKind(ErrValidation, err))
+		}
+
+		return &p, pkgErrors.WithStack(WithKind(ErrValidation, err))
+	}
+
+	return &p, nil
+}
+
+// dataReader remembers the first error reading the underlying reader, so that errors
+// caused by malformed data can be told apart from errors reading it. It also counts
+// the bytes read, which can be queried concurrently.
+type dataReader struct {
+	r   io.Reader
+	err error
+	n   atomic.Int64
+}
+
+// Read reads from the underlying reader.
+func (d *dataReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.n.Add(int64(n))
+	if err != nil && !errors.Is(err, io.EOF) && d.err == nil {
+		d.err = err
+	}
+
+	return n, err
+}
+
+// bytesRead returns the number of bytes read so far.
+func (d *dataReader) bytesRead() int64 {
+	return d.n.Load()
+}
+
+// decodeError marks an error decoding the data as an ErrValidation error, unless
+// it has been caused by reading the data.
+func (d *dataReader) decodeError(err error) error {
+	if d.err != nil {
+		return pkgErrors.WithStack(err)
+	}
+
+	return pkgErrors.WithStack(WithKind(ErrValidation, err))
+}
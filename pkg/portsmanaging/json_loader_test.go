@@ -1,6 +1,11 @@
 package portsmanaging_test
 
 import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,22 +18,24 @@ import (
 
 var expectedPorts = map[string]*portsmanaging.MaritimePort{
 	"AEAJM": {
+		Version:     1,
 		ID:          "AEAJM",
 		Name:        "Ajman",
 		City:        "Ajman",
 		Country:     "United Arab Emirates",
 		Alias:       []string{},
 		Regions:     []string{},
-		Coordinates: []float64{55.5136433, 25.4052165},
+		Coordinates: portsmanaging.NewGeoPoint(25.4052165, 55.5136433),
 		Province:    "Ajman",
 		Timezone:    "Asia/Dubai",
 		Unlocs:      []string{"AEAJM"},
 		Code:        "52000",
 	},
 	"AEAUH": {
+		Version:     1,
 		ID:          "AEAUH",
 		Name:        "Abu Dhabi",
-		Coordinates: []float64{54.37, 24.47},
+		Coordinates: portsmanaging.NewGeoPoint(24.47, 54.37),
 		City:        "Abu Dhabi",
 		Province:    "Abu Z¸aby [Abu Dhabi]",
 		Country:     "United Arab Emirates",
@@ -39,9 +46,10 @@ var expectedPorts = map[string]*portsmanaging.MaritimePort{
 		Code:        "52001",
 	},
 	"AEDXB": {
+		Version:     1,
 		ID:          "AEDXB",
 		Name:        "Dubai",
-		Coordinates: []float64{55.27, 25.25},
+		Coordinates: portsmanaging.NewGeoPoint(25.25, 55.27),
 		City:        "Dubai",
 		Province:    "Dubayy [Dubai]",
 		Country:     "United Arab Emirates",
@@ -60,14 +68,175 @@ func TestJSONLoader(t *testing.T) {
 		portsStore := memory.NewPortsRepository()
 		loader := portsmanaging.NewJSONLoader(portsStore)
 
-		err := loader.LoadJSONFile("../../testdata/test_data_ports.json")
+		report, err := loader.LoadJSONFile(context.Background(), "../../testdata/test_data_ports.json")
 		require.NoError(t, err)
+		assert.Equal(t, 3, report.Loaded)
+		assert.Empty(t, report.Rejected)
 
-		storedPorts, err := portsStore.GetAllPorts()
+		storedPorts, err := portsStore.GetAllPorts(context.Background())
 		require.NoError(t, err)
 
 		for _, port := range storedPorts {
 			assert.Equal(t, port, expectedPorts[port.ID])
 		}
 	})
+	t.Run("should stop loading once the context is done", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewJSONLoader(portsStore)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := loader.LoadJSONFile(ctx, "../../testdata/test_data_ports.json")
+		require.ErrorIs(t, err, context.Canceled)
+
+		storedPorts, err := portsStore.GetAllPorts(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, storedPorts)
+	})
+
+	t.Run("should reject an invalid port", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewJSONLoader(portsStore)
+
+		report, err := loader.Load(context.Background(), strings.NewReader(`{
+			"AEAJM": {"name": "Ajman", "country": "United Arab Emirates", "unlocs": ["AEAJM"]},
+			"ajman": {"name": "Ajman", "country": "United Arab Emirates", "timezone": "Asia/Ajman"}
+		}`))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Len(t, portsmanaging.Violations(err), 2)
+		assert.Equal(t, 1, report.Loaded)
+
+		storedPorts, err := portsStore.GetAllPorts(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, storedPorts, 1)
+	})
+
+	t.Run("should load NDJSON data properly", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewJSONLoader(portsStore)
+
+		report, err := loader.LoadNDJSON(context.Background(), strings.NewReader(
+			`{"id": "AEAJM", "name": "Ajman", "country": "United Arab Emirates", "unlocs": ["AEAJM"]}
+{"id": "AEDXB", "name": "Dubai", "country": "United Arab Emirates", "coordinates": [55.27, 25.25]}
+`))
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Loaded)
+
+		p, err := portsStore.GetPortByID(context.Background(), "AEDXB")
+		require.NoError(t, err)
+		assert.Equal(t, "Dubai", p.Name)
+		assert.Equal(t, portsmanaging.NewGeoPoint(25.25, 55.27), p.Coordinates)
+	})
+	t.Run("should skip and report malformed and invalid ports in lenient mode", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewJSONLoader(portsStore)
+		loader.Lenient = true
+
+		data := `{
+			"AEAJM": {"name": "Ajman", "country": "United Arab Emirates", "unlocs": ["AEAJM"]},
+			"AEBAD": {"name": "Bad", "country": "United Arab Emirates", "coordinates": "north"},
+			"ajman": {"name": "Ajman", "country": "United Arab Emirates"},
+			"AEDXB": {"name": "Dubai", "country": "United Arab Emirates", "unlocs": ["AEDXB"]}
+		}`
+
+		report, err := loader.Load(context.Background(), strings.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Loaded)
+		require.Len(t, report.Rejected, 2)
+
+		for _, rejected := range report.Rejected {
+			assert.True(t, strings.HasPrefix(strings.TrimLeft(data[rejected.Offset:], ": "), rejected.Raw))
+		}
+
+		assert.Equal(t, "AEBAD", report.Rejected[0].ID)
+		assert.Equal(t, `{"name": "Bad", "country": "United Arab Emirates", "coordinates": "north"}`,
+			report.Rejected[0].Raw)
+		assert.Contains(t, report.Rejected[0].Reason, "coordinates must be a [longitude, latitude] array")
+
+		assert.Equal(t, "ajman", report.Rejected[1].ID)
+		assert.Equal(t, "invalid port entry with ID 'ajman': id must be a valid UN/LOCODE, got 'ajman'",
+			report.Rejected[1].Reason)
+		assert.Equal(t, []portsmanaging.Violation{{Field: "id", Message: "id must be a valid UN/LOCODE, got 'ajman'"}},
+			report.Rejected[1].Violations)
+
+		storedPorts, err := portsStore.GetAllPorts(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, storedPorts, 2)
+	})
+
+	t.Run("should skip and report malformed and invalid NDJSON lines in lenient mode", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewJSONLoader(portsStore)
+		loader.Lenient = true
+
+		report, err := loader.LoadNDJSON(context.Background(), strings.NewReader(
+			`{"id": "AEAJM", "name": "Ajman", "country": "United Arab Emirates"}
+{"id": "AEBAD", "name": "Bad",
+
+{"id": "AEAUH", "name": "Abu Dhabi", "country": "United Arab Emirates", "unlocs": "AEAUH"}
+{"id": "AEDXB", "name": "Dubai", "country": "United Arab Emirates"}`))
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Loaded)
+		require.Len(t, report.Rejected, 2)
+
+		assert.Equal(t, &portsmanaging.RejectedRecord{
+			Offset: 68,
+			Line:   2,
+			Reason: "unexpected end of JSON input",
+			Raw:    `{"id": "AEBAD", "name": "Bad",`,
+		}, report.Rejected[0])
+
+		assert.Equal(t, "AEAUH", report.Rejected[1].ID)
+		assert.Equal(t, 4, report.Rejected[1].Line)
+		assert.Contains(t, report.Rejected[1].Reason, "cannot unmarshal string")
+	})
+
+	t.Run("should fail once the error budget is exceeded", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewJSONLoader(portsStore)
+		loader.Lenient = true
+		loader.ErrorBudget = 1
+
+		report, err := loader.Load(context.Background(), strings.NewReader(`{
+			"aeajm": {"name": "Ajman", "country": "United Arab Emirates"},
+			"AEAUH": {"name": "Abu Dhabi", "country": "United Arab Emirates"},
+			"aedxb": {"name": "Dubai", "country": "United Arab Emirates"},
+			"AEFJR": {"name": "Fujairah", "country": "United Arab Emirates"}
+		}`))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.EqualError(t, err, "rejected more than 1 records: "+
+			"invalid port entry with ID 'aedxb': id must be a valid UN/LOCODE, got 'aedxb'")
+		assert.Equal(t, 1, report.Loaded)
+		assert.Len(t, report.Rejected, 2)
+	})
+
+	t.Run("should write the load report to a file", func(t *testing.T) {
+		dir := t.TempDir()
+		dataPath := filepath.Join(dir, "ports.json")
+		reportPath := filepath.Join(dir, "report.json")
+
+		require.NoError(t, os.WriteFile(dataPath, []byte(`{
+			"AEAJM": {"name": "Ajman", "country": "United Arab Emirates"},
+			"AEBAD": {"name": "Bad"}
+		}`), 0o600))
+
+		loader := portsmanaging.NewJSONLoader(memory.NewPortsRepository())
+		loader.Lenient = true
+		loader.ReportPath = reportPath
+
+		report, err := loader.LoadJSONFile(context.Background(), dataPath)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(reportPath)
+		require.NoError(t, err)
+
+		var written portsmanaging.LoadReport
+		require.NoError(t, json.Unmarshal(data, &written))
+		assert.Equal(t, report, &written)
+		assert.Equal(t, 1, written.Loaded)
+		require.Len(t, written.Rejected, 1)
+		assert.Equal(t, "AEBAD", written.Rejected[0].ID)
+		assert.Equal(t, `{"name": "Bad"}`, written.Rejected[0].Raw)
+	})
 }
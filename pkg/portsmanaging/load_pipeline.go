@@ -0,0 +1,369 @@
+package portsmanaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+const (
+	// defaultQueueSize is the number of records queued per worker by default.
+	defaultQueueSize = 64
+	// defaultProgressInterval is the number of records between progress reports by default.
+	defaultProgressInterval = 1000
+)
+
+// LoadProgress represents the progress of loading ports data.
+type LoadProgress struct {
+	// Records is the number of processed records, either loaded or rejected.
+	Records  int
+	Loaded   int
+	Rejected int
+	// Bytes is the number of bytes of data read so far.
+	Bytes   int64
+	Elapsed time.Duration
+}
+
+// RecordsPerSecond returns the number of records processed per second.
+func (p LoadProgress) RecordsPerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(p.Records) / p.Elapsed.Seconds()
+}
+
+// BytesPerSecond returns the number of bytes of data read per second.
+func (p LoadProgress) BytesPerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(p.Bytes) / p.Elapsed.Seconds()
+}
+
+// loadRecord is a single record of ports data, numbered by its position in the data.
+type loadRecord struct {
+	seq int
+	// key is the port ID given by the key of a JSON object member, if any.
+	key      string
+	raw      []byte
+	rejected RejectedRecord
+	err      error
+}
+
+// nextRecord returns the next record of ports data or io.EOF once there are no more records.
+type nextRecord func() (*loadRecord, error)
+
+// load processes all records returned by next, either sequentially or concurrently by
+// Workers goroutines.
+func (l *JSONLoader) load(ctx context.Context, dr *dataReader, next nextRecord) (*LoadReport, error) {
+	c := newLoadCollector(l, dr)
+
+	if l.Workers > 1 {
+		l.loadConcurrently(ctx, c, next)
+	} else {
+		l.loadSequentially(ctx, c, next)
+	}
+
+	return c.result()
+}
+
+// loadSequentially processes the records returned by next one after another.
+func (l *JSONLoader) loadSequentially(ctx context.Context, c *loadCollector, next nextRecord) {
+	for seq := 0; int64(seq) <= c.stop.Load(); seq++ {
+		if err := ctx.Err(); err != nil {
+			c.fail(&loadRecord{seq: seq, err: pkgErrors.WithStack(err)})
+
+			return
+		}
+
+		rec, err := next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		if err != nil {
+			c.fail(&loadRecord{seq: seq, err: err})
+
+			return
+		}
+
+		rec.seq = seq
+		l.process(ctx, rec)
+		c.collect(rec)
+	}
+}
+
+// loadConcurrently processes the records returned by next in a pipeline. A single goroutine
+// reads the records and dispatches them to Workers goroutines, each of which decodes, validates
+// and stores the ports of its bounded queue, so that reading blocks while all queues are full.
+// Records are dispatched by port ID, which keeps ports with the same ID in the order of the data.
+//
+// Once a record fails, records which come after it are no longer read nor stored, while
+// those before it still are, so that the outcome is the same as of loading sequentially,
+// except that ports after the failed record may have been stored already. Such ports are
+// not reported as loaded though.
+func (l *JSONLoader) loadConcurrently(ctx context.Context, c *loadCollector, next nextRecord) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queueSize := l.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	queues := make([]chan *loadRecord, l.Workers)
+	for i := range queues {
+		queues[i] = make(chan *loadRecord, queueSize)
+	}
+
+	results := make(chan *loadRecord, l.Workers)
+	dispatched := make(chan *loadRecord, 1)
+
+	go func() {
+		dispatched <- dispatch(ctx, next, queues, &c.stop)
+	}()
+
+	var wg sync.WaitGroup
+
+	wg.Add(len(queues))
+
+	for _, queue := range queues {
+		go func(queue <-chan *loadRecord) {
+			defer wg.Done()
+
+			for rec := range queue {
+				if int64(rec.seq) > c.stop.Load() {
+					continue
+				}
+
+				l.process(ctx, rec)
+				results <- rec
+			}
+		}(queue)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for rec := range results {
+		c.collect(rec)
+	}
+
+	if rec := <-dispatched; rec.err != nil {
+		c.fail(rec)
+	}
+}
+
+// dispatch queues the records returned by next to the queue of their port ID until there
+// are no more records, next fails, ctx is done or the next record comes after stop. It
+// closes the queues and returns a record holding the error which ended reading, if any.
+func dispatch(ctx context.Context, next nextRecord, queues []chan *loadRecord, stop *atomic.Int64) *loadRecord {
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+	}()
+
+	for seq := 0; int64(seq) <= stop.Load(); seq++ {
+		rec, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return &loadRecord{seq: seq, err: err}
+		}
+
+		rec.seq = seq
+
+		select {
+		case queues[shard(rec, len(queues))] <- rec:
+		case <-ctx.Done():
+			return &loadRecord{seq: seq, err: pkgErrors.WithStack(ctx.Err())}
+		}
+	}
+
+	return &loadRecord{}
+}
+
+// shard returns the index of the queue of a record among n queues, given by its port ID.
+func shard(rec *loadRecord, n int) int {
+	id := rec.key
+	if id == "" {
+		var port struct {
+			ID string `json:"id"`
+		}
+
+		if err := json.Unmarshal(rec.raw, &port); err == nil {
+			id = port.ID
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(id))
+
+	return int(h.Sum32() % uint32(n))
+}
+
+// loadCollector collects the outcome of processed records, reports the progress of loading
+// and tracks the position in the data after which records are no longer needed.
+type loadCollector struct {
+	lenient     bool
+	errorBudget int
+	progress    func(LoadProgress)
+	interval    int
+	dr          *dataReader
+	start       time.Time
+
+	records int
+	// loaded holds the sequence numbers of the loaded records.
+	loaded   []int
+	rejected []*loadRecord
+	failed   *loadRecord
+	// stop is the sequence number of the last record needed to tell the outcome of loading.
+	stop atomic.Int64
+}
+
+// newLoadCollector is a constructor function for loadCollector.
+func newLoadCollector(l *JSONLoader, dr *dataReader) *loadCollector {
+	c := &loadCollector{
+		lenient:     l.Lenient,
+		errorBudget: l.ErrorBudget,
+		progress:    l.Progress,
+		interval:    l.ProgressInterval,
+		dr:          dr,
+		start:       time.Now(),
+	}
+
+	if c.interval <= 0 {
+		c.interval = defaultProgressInterval
+	}
+
+	c.stop.Store(math.MaxInt64)
+
+	return c
+}
+
+// collect records the outcome of a processed record. Records which are not valid are
+// rejected in lenient mode, while any other failure ends loading.
+func (c *loadCollector) collect(rec *loadRecord) {
+	c.records++
+
+	switch {
+	case rec.err == nil:
+		c.loaded = append(c.loaded, rec.seq)
+	case c.lenient && errors.Is(rec.err, ErrValidation):
+		c.rejected = append(c.rejected, rec)
+
+		if c.errorBudget > 0 && len(c.rejected) > c.errorBudget {
+			c.stopAfter(c.lastRejected())
+		}
+	default:
+		c.fail(rec)
+	}
+
+	if c.progress != nil && c.records%c.interval == 0 {
+		c.progress(c.snapshot())
+	}
+}
+
+// fail records a failure ending loading, unless an earlier record has already failed.
+func (c *loadCollector) fail(rec *loadRecord) {
+	if c.failed == nil || rec.seq < c.failed.seq {
+		c.failed = rec
+	}
+
+	c.stopAfter(rec.seq)
+}
+
+// stopAfter makes records after seq no longer needed.
+func (c *loadCollector) stopAfter(seq int) {
+	if int64(seq) < c.stop.Load() {
+		c.stop.Store(int64(seq))
+	}
+}
+
+// lastRejected returns the sequence number of the last rejected record.
+func (c *loadCollector) lastRejected() int {
+	last := 0
+
+	for _, rec := range c.rejected {
+		if rec.seq > last {
+			last = rec.seq
+		}
+	}
+
+	return last
+}
+
+// snapshot returns the current progress of loading.
+func (c *loadCollector) snapshot() LoadProgress {
+	return LoadProgress{
+		Records:  c.records,
+		Loaded:   len(c.loaded),
+		Rejected: len(c.rejected),
+		Bytes:    c.dr.bytesRead(),
+		Elapsed:  time.Since(c.start),
+	}
+}
+
+// result reports the final progress of loading and returns its report along with the error
+// ending it, if any. Loaded and rejected records are reported in the order of the data up to
+// the first failure or the record exceeding the error budget, whichever comes first, so that
+// the report does not depend on how far concurrent workers got past it.
+func (c *loadCollector) result() (*LoadReport, error) {
+	if c.progress != nil {
+		c.progress(c.snapshot())
+	}
+
+	sort.Slice(c.rejected, func(i, j int) bool {
+		return c.rejected[i].seq < c.rejected[j].seq
+	})
+
+	var err error
+
+	last := math.MaxInt
+	rejected := c.rejected
+
+	if c.failed != nil {
+		err = c.failed.err
+		last = c.failed.seq
+		n := sort.Search(len(rejected), func(i int) bool {
+			return rejected[i].seq > last
+		})
+		rejected = rejected[:n]
+	}
+
+	if c.errorBudget > 0 && len(rejected) > c.errorBudget {
+		rejected = rejected[:c.errorBudget+1]
+		err = pkgErrors.Wrapf(rejected[c.errorBudget].err, "rejected more than %d records", c.errorBudget)
+		last = rejected[c.errorBudget].seq
+	}
+
+	report := NewLoadReport()
+
+	for _, seq := range c.loaded {
+		if seq < last {
+			report.Loaded++
+		}
+	}
+
+	for _, rec := range rejected {
+		report.reject(&rec.rejected, rec.raw, rec.err)
+	}
+
+	return report, err
+}
@@ -0,0 +1,306 @@
+package portsmanaging_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+)
+
+func TestJSONLoaderConcurrently(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should load the same ports as loading sequentially", func(t *testing.T) {
+		sequential := memory.NewPortsRepository()
+		_, err := portsmanaging.NewJSONLoader(sequential).LoadJSONFile(
+			context.Background(), "../../fixtures/ports.json")
+		require.NoError(t, err)
+
+		concurrent := memory.NewPortsRepository()
+		loader := portsmanaging.NewJSONLoader(concurrent)
+		loader.Workers = 4
+
+		report, err := loader.LoadJSONFile(context.Background(), "../../fixtures/ports.json")
+		require.NoError(t, err)
+		assert.Equal(t, 1632, report.Loaded)
+		assert.Empty(t, report.Rejected)
+		assertSamePorts(t, sequential, concurrent)
+	})
+
+	t.Run("should store ports with the same ID in the order of the data", func(t *testing.T) {
+		var data strings.Builder
+
+		for i := 0; i < 100; i++ {
+			fmt.Fprintf(&data, `{"id": "AEAJM", "name": "Ajman %d", "country": "United Arab Emirates"}`+"\n", i)
+		}
+
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewJSONLoader(portsStore)
+		loader.Workers = 4
+		loader.QueueSize = 1
+
+		report, err := loader.LoadNDJSON(context.Background(), strings.NewReader(data.String()))
+		require.NoError(t, err)
+		assert.Equal(t, 100, report.Loaded)
+
+		p, err := portsStore.GetPortByID(context.Background(), "AEAJM")
+		require.NoError(t, err)
+		assert.Equal(t, "Ajman 99", p.Name)
+		assert.Equal(t, uint64(100), p.Version)
+	})
+
+	t.Run("should report the first invalid port", func(t *testing.T) {
+		data := ndjsonPorts(500, 100, 200, 300)
+
+		sequential, err := portsmanaging.NewJSONLoader(memory.NewPortsRepository()).LoadNDJSON(
+			context.Background(), strings.NewReader(data))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Equal(t, 100, sequential.Loaded)
+
+		for i := 0; i < 20; i++ {
+			loader := portsmanaging.NewJSONLoader(memory.NewPortsRepository())
+			loader.Workers = 8
+			loader.QueueSize = 1
+
+			report, err := loader.LoadNDJSON(context.Background(), strings.NewReader(data))
+			require.ErrorIs(t, err, portsmanaging.ErrValidation)
+			assert.EqualError(t, err, "invalid port entry with ID 'XXAC8': country is required")
+			assert.Equal(t, sequential.Loaded, report.Loaded)
+			assert.Empty(t, report.Rejected)
+		}
+	})
+
+	t.Run("should report rejected ports in the order of the data", func(t *testing.T) {
+		data := ndjsonPorts(500, 300, 100, 200, 400)
+
+		loader := portsmanaging.NewJSONLoader(memory.NewPortsRepository())
+		loader.Workers = 8
+		loader.Lenient = true
+
+		report, err := loader.LoadNDJSON(context.Background(), strings.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, 496, report.Loaded)
+		assert.Equal(t, []int{101, 201, 301, 401}, rejectedLines(report))
+
+		for i := 0; i < 20; i++ {
+			loader = portsmanaging.NewJSONLoader(memory.NewPortsRepository())
+			loader.Workers = 8
+			loader.QueueSize = 1
+			loader.Lenient = true
+			loader.ErrorBudget = 2
+
+			report, err = loader.LoadNDJSON(context.Background(), strings.NewReader(data))
+			require.ErrorIs(t, err, portsmanaging.ErrValidation)
+			assert.EqualError(t, err, "rejected more than 2 records: "+
+				"invalid port entry with ID 'XXAI4': country is required")
+			assert.Equal(t, 298, report.Loaded)
+			assert.Equal(t, []int{101, 201, 301}, rejectedLines(report))
+		}
+	})
+
+	t.Run("should report the progress of loading", func(t *testing.T) {
+		data := ndjsonPorts(500, 250)
+
+		var progress []portsmanaging.LoadProgress
+
+		loader := portsmanaging.NewJSONLoader(memory.NewPortsRepository())
+		loader.Workers = 4
+		loader.Lenient = true
+		loader.ProgressInterval = 100
+		loader.Progress = func(p portsmanaging.LoadProgress) {
+			progress = append(progress, p)
+		}
+
+		_, err := loader.LoadNDJSON(context.Background(), strings.NewReader(data))
+		require.NoError(t, err)
+		require.Len(t, progress, 6)
+
+		for i, p := range progress[:5] {
+			assert.Equal(t, (i+1)*100, p.Records)
+			assert.Equal(t, p.Records, p.Loaded+p.Rejected)
+			assert.Positive(t, p.Bytes)
+		}
+
+		last := progress[5]
+		assert.Equal(t, 500, last.Records)
+		assert.Equal(t, 499, last.Loaded)
+		assert.Equal(t, 1, last.Rejected)
+		assert.Equal(t, int64(len(data)), last.Bytes)
+		assert.Positive(t, last.RecordsPerSecond())
+		assert.Positive(t, last.BytesPerSecond())
+	})
+
+	t.Run("should stop loading once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		loader := portsmanaging.NewJSONLoader(memory.NewPortsRepository())
+		loader.Workers = 4
+		loader.ProgressInterval = 10
+		loader.Progress = func(portsmanaging.LoadProgress) {
+			cancel()
+		}
+
+		report, err := loader.LoadNDJSON(ctx, strings.NewReader(ndjsonPorts(5000)))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, report.Loaded, 5000)
+	})
+
+	t.Run("should not read ahead of the workers", func(t *testing.T) {
+		release := make(chan struct{})
+		portsStore := &blockingPortsStore{PortsStore: memory.NewPortsRepository(), release: release}
+
+		pr, pw := io.Pipe()
+
+		var written atomic.Int64
+
+		go func() {
+			for _, line := range strings.SplitAfter(ndjsonPorts(5000), "\n") {
+				if _, err := io.WriteString(pw, line); err != nil {
+					return
+				}
+
+				written.Add(1)
+			}
+
+			pw.Close()
+		}()
+
+		loader := portsmanaging.NewJSONLoader(portsStore)
+		loader.Workers = 2
+		loader.QueueSize = 1
+
+		done := make(chan error, 1)
+
+		go func() {
+			report, err := loader.LoadNDJSON(context.Background(), pr)
+			if err == nil && report.Loaded != 5000 {
+				err = fmt.Errorf("loaded %d ports", report.Loaded)
+			}
+
+			done <- err
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Less(t, written.Load(), int64(200))
+
+		close(release)
+		require.NoError(t, <-done)
+	})
+}
+
+func BenchmarkJSONLoader(b *testing.B) {
+	data, err := os.ReadFile("../../fixtures/ports.json")
+	require.NoError(b, err)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+
+			for i := 0; i < b.N; i++ {
+				loader := portsmanaging.NewJSONLoader(memory.NewPortsRepository())
+				loader.Workers = workers
+
+				_, err := loader.Load(context.Background(), strings.NewReader(string(data)))
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+// BenchmarkJSONLoaderNDJSON measures decoding and validating ports without storing them.
+func BenchmarkJSONLoaderNDJSON(b *testing.B) {
+	data := ndjsonPorts(20000)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+
+			for i := 0; i < b.N; i++ {
+				loader := portsmanaging.NewJSONLoader(discardPortsStore{})
+				loader.Workers = workers
+
+				_, err := loader.LoadNDJSON(context.Background(), strings.NewReader(data))
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+// blockingPortsStore is a PortsStore whose upserts block until release is closed.
+type blockingPortsStore struct {
+	portsmanaging.PortsStore
+	release <-chan struct{}
+}
+
+func (s *blockingPortsStore) UpsertPort(
+	ctx context.Context,
+	port *portsmanaging.MaritimePort,
+	cond portsmanaging.Precondition,
+) (*portsmanaging.MaritimePort, bool, error) {
+	<-s.release
+
+	return s.PortsStore.UpsertPort(ctx, port, cond)
+}
+
+// discardPortsStore is a PortsStore which discards upserted ports.
+type discardPortsStore struct {
+	portsmanaging.PortsStore
+}
+
+func (discardPortsStore) UpsertPort(
+	_ context.Context,
+	port *portsmanaging.MaritimePort,
+	_ portsmanaging.Precondition,
+) (*portsmanaging.MaritimePort, bool, error) {
+	return port, false, nil
+}
+
+// ndjsonPorts returns n distinct ports as NDJSON, the ports at the given 0-based
+// positions lacking a country.
+func ndjsonPorts(n int, invalid ...int) string {
+	const symbols = "ABCDEFGHIJKLMNOPQRSTUVWXYZ23456789"
+
+	var data strings.Builder
+
+	for i := 0; i < n; i++ {
+		id := "XX" + string([]byte{
+			symbols[i/len(symbols)/len(symbols)%len(symbols)],
+			symbols[i/len(symbols)%len(symbols)],
+			symbols[i%len(symbols)],
+		})
+		country := "Atlantis"
+
+		for _, j := range invalid {
+			if i == j {
+				country = ""
+			}
+		}
+
+		fmt.Fprintf(&data, `{"id": %q, "name": "Port %d", "city": "City %d", "country": %q, `+
+			`"coordinates": [%d.5, %d.25], "timezone": "Europe/Sofia", "unlocs": [%q]}`+"\n",
+			id, i, i, country, i%180, i%90, id)
+	}
+
+	return data.String()
+}
+
+// rejectedLines returns the line numbers of the records rejected while loading NDJSON data.
+func rejectedLines(report *portsmanaging.LoadReport) []int {
+	lines := make([]int, 0, len(report.Rejected))
+	for _, rejected := range report.Rejected {
+		lines = append(lines, rejected.Line)
+	}
+
+	return lines
+}
@@ -0,0 +1,79 @@
+package portsmanaging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// maxRawSnippet is the maximum number of bytes of a rejected record kept in a LoadReport.
+const maxRawSnippet = 256
+
+// LoadReport summarizes loading ports data. All loaders store ports as they go, so ports
+// loaded before a failure remain in the store and are counted as loaded.
+type LoadReport struct {
+	Loaded   int               `json:"loaded"`
+	Rejected []*RejectedRecord `json:"rejected"`
+}
+
+// RejectedRecord describes a record which has been skipped while loading ports data
+// in lenient mode. Offset is the position in bytes following the port ID key of the
+// record in a JSON object or the position of its line in newline-delimited data, while
+// Line is its line number in newline-delimited data. Source names the source of the data
+// when loading several of them.
+type RejectedRecord struct {
+	Source     string      `json:"source,omitempty"`
+	ID         string      `json:"id,omitempty"`
+	Offset     int64       `json:"offset"`
+	Line       int         `json:"line,omitempty"`
+	Reason     string      `json:"reason"`
+	Violations []Violation `json:"violations,omitempty"`
+	Raw        string      `json:"raw"`
+}
+
+// NewLoadReport is a constructor function for LoadReport.
+func NewLoadReport() *LoadReport {
+	return &LoadReport{
+		Rejected: []*RejectedRecord{},
+	}
+}
+
+// reject records a rejected record given its raw data and the reason it has been rejected.
+func (r *LoadReport) reject(record *RejectedRecord, raw []byte, reason error) {
+	record.Reason = reason.Error()
+	record.Violations = Violations(reason)
+	record.Raw = rawSnippet(raw)
+
+	r.Rejected = append(r.Rejected, record)
+}
+
+// WriteFile writes the report as JSON to the file at path, replacing any existing file.
+func (r *LoadReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return pkgErrors.WithStack(err)
+	}
+
+	reportPath, err := filepath.Abs(path)
+	if err != nil {
+		return pkgErrors.Wrapf(err, "cannot access load report file %s", path)
+	}
+
+	if err = os.WriteFile(reportPath, append(data, '\n'), 0o644); err != nil {
+		return pkgErrors.Wrapf(err, "cannot write load report to file %s", reportPath)
+	}
+
+	return nil
+}
+
+// rawSnippet returns the raw data of a record, truncated to maxRawSnippet bytes.
+func rawSnippet(raw []byte) string {
+	if len(raw) <= maxRawSnippet {
+		return strings.ToValidUTF8(string(raw), "")
+	}
+
+	return strings.ToValidUTF8(string(raw[:maxRawSnippet]), "") + "..."
+}
@@ -2,15 +2,48 @@ package portsmanaging
 
 // MaritimePort represents a maritime port.
 type MaritimePort struct {
+	// Version is assigned by the PortsStore. It starts at 1 when the port is created
+	// and increases by 1 with every modification. A port created again after being deleted
+	// continues from the last version of the deleted port, so that versions of a port ID
+	// are never reused.
+	Version     uint64    `json:"-"`
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	City        string    `json:"city"`
 	Country     string    `json:"country"`
 	Alias       []string  `json:"alias"`
 	Regions     []string  `json:"regions"`
-	Coordinates []float64 `json:"coordinates"`
+	Coordinates *GeoPoint `json:"coordinates" swaggertype:"array,number"`
 	Province    string    `json:"province"`
 	Timezone    string    `json:"timezone"`
 	Unlocs      []string  `json:"unlocs"`
 	Code        string    `json:"code,omitempty"`
 }
+
+// Clone returns a deep copy of the port, which shares no memory with it.
+func (p *MaritimePort) Clone() *MaritimePort {
+	if p == nil {
+		return nil
+	}
+
+	c := *p
+	c.Alias = cloneStrings(p.Alias)
+	c.Regions = cloneStrings(p.Regions)
+	c.Unlocs = cloneStrings(p.Unlocs)
+
+	if p.Coordinates != nil {
+		coords := *p.Coordinates
+		c.Coordinates = &coords
+	}
+
+	return &c
+}
+
+// cloneStrings copies ss, keeping nil and empty slices apart as they are encoded differently.
+func cloneStrings(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(ss)), ss...)
+}
@@ -0,0 +1,466 @@
+package portsmanaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// PatchFormat represents the format of a partial update document.
+type PatchFormat int
+
+const (
+	// MergePatch is a JSON Merge Patch document as defined in RFC 7396.
+	MergePatch PatchFormat = iota
+	// JSONPatch is a JSON Patch document as defined in RFC 6902.
+	JSONPatch
+)
+
+// ApplyPatch applies patch of the given format to the JSON representation of port
+// and returns the resulting port. The original port is left unmodified.
+//
+// Clearing semantics follow from the JSON representation: removing a member, either
+// via a `null` value in a merge patch or a `remove` operation in a JSON patch, resets
+// list fields (`alias`, `regions`, `unlocs`) to an empty list, `coordinates` to null
+// and string fields (`name`, `code`, etc.) to an empty string. The port ID cannot be
+// changed and members unknown to MaritimePort are rejected. Invalid patches result in an
+// ErrValidation error, while a failed JSON Patch `test` operation results in ErrConflict.
+func ApplyPatch(port *MaritimePort, patch []byte, format PatchFormat) (*MaritimePort, error) {
+	docBytes, err := json.Marshal(port)
+	if err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	var patchedBytes []byte
+
+	switch format {
+	case MergePatch:
+		patchedBytes, err = ApplyMergePatch(docBytes, patch)
+	case JSONPatch:
+		patchedBytes, err = ApplyJSONPatch(docBytes, patch)
+	default:
+		return nil, NewError(ErrValidation, "unsupported patch format %d", format)
+	}
+
+	if err != nil {
+		return nil, WithKind(ErrValidation, err)
+	}
+
+	var patched MaritimePort
+
+	dec := json.NewDecoder(bytes.NewReader(patchedBytes))
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(&patched); err != nil {
+		return nil, WithKind(ErrValidation, pkgErrors.Wrap(err, "patched port is invalid"))
+	}
+
+	if patched.ID != port.ID {
+		return nil, NewViolationError("id", "patch cannot change port ID '%s'", port.ID)
+	}
+
+	normalizeLists(&patched)
+
+	return &patched, nil
+}
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7396) to a JSON document.
+func ApplyMergePatch(doc []byte, patch []byte) ([]byte, error) {
+	var target, patchValue any
+
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, pkgErrors.Wrap(err, "invalid target document")
+	}
+
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, pkgErrors.Wrap(err, "invalid merge patch document")
+	}
+
+	return json.Marshal(mergePatch(target, patchValue))
+}
+
+func mergePatch(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+		} else {
+			targetObj[k] = mergePatch(targetObj[k], v)
+		}
+	}
+
+	return targetObj
+}
+
+// jsonPatchOperation is a single operation of a JSON Patch document.
+type jsonPatchOperation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) to a JSON document. Operations are
+// applied in order and the patch fails as a whole if any of them fails.
+func ApplyJSONPatch(doc []byte, patch []byte) ([]byte, error) {
+	var (
+		target any
+		ops    []jsonPatchOperation
+	)
+
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, pkgErrors.Wrap(err, "invalid target document")
+	}
+
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, pkgErrors.Wrap(err, "invalid JSON patch document")
+	}
+
+	for i, op := range ops {
+		var err error
+
+		target, err = applyJSONPatchOperation(target, op)
+		if err != nil {
+			return nil, pkgErrors.Wrapf(err, "JSON patch operation %d (%s) failed", i, op.Op)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func applyJSONPatchOperation(doc any, op jsonPatchOperation) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("missing 'path' member")
+	}
+
+	path, err := parseJSONPointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing 'value' member")
+		}
+
+		var value any
+
+		if err = json.Unmarshal(*op.Value, &value); err != nil {
+			return nil, pkgErrors.Wrap(err, "invalid 'value' member")
+		}
+
+		switch op.Op {
+		case "add":
+			return pointerAdd(doc, path, value)
+		case "replace":
+			return pointerReplace(doc, path, value)
+		default:
+			return doc, pointerTest(doc, path, value)
+		}
+	case "remove":
+		doc, _, err = pointerRemove(doc, path)
+
+		return doc, err
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("missing 'from' member")
+		}
+
+		from, errFrom := parseJSONPointer(*op.From)
+		if errFrom != nil {
+			return nil, errFrom
+		}
+
+		var value any
+
+		if op.Op == "move" {
+			if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+				return nil, fmt.Errorf("cannot move a value into one of its children")
+			}
+
+			doc, value, err = pointerRemove(doc, from)
+		} else {
+			value, err = pointerGet(doc, from)
+			value = deepCopy(value)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return pointerAdd(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown operation '%s'", op.Op)
+	}
+}
+
+// parseJSONPointer splits a JSON Pointer (RFC 6901) into its unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer '%s'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// pointerUpdate walks doc along path and lets fn modify the container holding the
+// last reference token. Containers are rebuilt bottom-up, since arrays may change size.
+func pointerUpdate(doc any, path []string, fn func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := pointerChild(doc, path[0], false)
+	if err != nil {
+		return nil, err
+	}
+
+	newChild, err := pointerUpdate(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c := doc.(type) {
+	case map[string]any:
+		c[path[0]] = newChild
+	case []any:
+		idx, _ := arrayIndex(path[0], len(c), false)
+		c[idx] = newChild
+	}
+
+	return doc, nil
+}
+
+func pointerGet(doc any, path []string) (any, error) {
+	var err error
+
+	for _, token := range path {
+		doc, err = pointerChild(doc, token, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+func pointerAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return pointerUpdate(doc, path, func(container any, token string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[token] = value
+
+			return c, nil
+		case []any:
+			idx, err := arrayIndex(token, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+
+			c = append(c, nil)
+			copy(c[idx+1:], c[idx:])
+			c[idx] = value
+
+			return c, nil
+		default:
+			return nil, fmt.Errorf("cannot add member '%s' to a scalar value", token)
+		}
+	})
+}
+
+func pointerRemove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	var removed any
+
+	doc, err := pointerUpdate(doc, path, func(container any, token string) (any, error) {
+		value, err := pointerChild(container, token, false)
+		if err != nil {
+			return nil, err
+		}
+
+		removed = value
+
+		switch c := container.(type) {
+		case map[string]any:
+			delete(c, token)
+
+			return c, nil
+		default:
+			arr := container.([]any)
+			idx, _ := arrayIndex(token, len(arr), false)
+
+			return append(arr[:idx], arr[idx+1:]...), nil
+		}
+	})
+
+	return doc, removed, err
+}
+
+func pointerReplace(doc any, path []string, value any) (any, error) {
+	if _, err := pointerGet(doc, path); err != nil {
+		return nil, err
+	}
+
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return pointerUpdate(doc, path, func(container any, token string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[token] = value
+
+			return c, nil
+		default:
+			arr := container.([]any)
+			idx, _ := arrayIndex(token, len(arr), false)
+			arr[idx] = value
+
+			return arr, nil
+		}
+	})
+}
+
+func pointerTest(doc any, path []string, value any) error {
+	actual, err := pointerGet(doc, path)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(actual, value) {
+		return NewError(ErrConflict, "test failed: value at '/%s' does not match", strings.Join(path, "/"))
+	}
+
+	return nil
+}
+
+// pointerChild returns the member of container referenced by token.
+func pointerChild(container any, token string, appendAllowed bool) (any, error) {
+	switch c := container.(type) {
+	case map[string]any:
+		v, ok := c[token]
+		if !ok {
+			return nil, fmt.Errorf("member '%s' does not exist", token)
+		}
+
+		return v, nil
+	case []any:
+		idx, err := arrayIndex(token, len(c), appendAllowed)
+		if err != nil {
+			return nil, err
+		}
+
+		return c[idx], nil
+	default:
+		return nil, fmt.Errorf("member '%s' does not exist", token)
+	}
+}
+
+// arrayIndex parses an array reference token. The "-" token and an index equal
+// to the array length both reference the position after the last element and
+// are only valid when appendAllowed is set.
+func arrayIndex(token string, length int, appendAllowed bool) (int, error) {
+	maxIdx := length - 1
+	if appendAllowed {
+		maxIdx = length
+	}
+
+	if token == "-" && appendAllowed {
+		return length, nil
+	}
+
+	if !isArrayIndex(token) {
+		return 0, fmt.Errorf("invalid array index '%s'", token)
+	}
+
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx > maxIdx {
+		return 0, fmt.Errorf("invalid array index '%s'", token)
+	}
+
+	return idx, nil
+}
+
+// isArrayIndex reports whether token is an array index as defined by RFC 6901,
+// i.e. '0' or digits without a leading zero.
+func isArrayIndex(token string) bool {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return false
+	}
+
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = deepCopy(e)
+		}
+
+		return m
+	case []any:
+		a := make([]any, len(v))
+		for i, e := range v {
+			a[i] = deepCopy(e)
+		}
+
+		return a
+	default:
+		return v
+	}
+}
+
+// normalizeLists replaces absent list fields with empty lists,
+// matching the representation of ports in the fixtures.
+func normalizeLists(p *MaritimePort) {
+	if p.Alias == nil {
+		p.Alias = []string{}
+	}
+
+	if p.Regions == nil {
+		p.Regions = []string{}
+	}
+
+	if p.Unlocs == nil {
+		p.Unlocs = []string{}
+	}
+}
@@ -0,0 +1,90 @@
+package portsmanaging_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should merge nested objects and remove null members", func(t *testing.T) {
+		patched, err := portsmanaging.ApplyMergePatch(
+			[]byte(`{"a": "b", "c": {"d": "e", "f": "g"}}`),
+			[]byte(`{"a": "z", "c": {"f": null}}`),
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a": "z", "c": {"d": "e"}}`, string(patched))
+	})
+
+	t.Run("should replace arrays as a whole", func(t *testing.T) {
+		patched, err := portsmanaging.ApplyMergePatch(
+			[]byte(`{"a": [1, 2]}`),
+			[]byte(`{"a": [3]}`),
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a": [3]}`, string(patched))
+	})
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should apply all operations in order", func(t *testing.T) {
+		patched, err := portsmanaging.ApplyJSONPatch(
+			[]byte(`{"a": [1, 2, 3], "b": {"c~d": 1}}`),
+			[]byte(`[
+				{"op": "add", "path": "/a/1", "value": 9},
+				{"op": "remove", "path": "/a/0"},
+				{"op": "replace", "path": "/b/c~0d", "value": 2},
+				{"op": "move", "from": "/b", "path": "/e"},
+				{"op": "test", "path": "/e", "value": {"c~d": 2}}
+			]`),
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a": [9, 2, 3], "e": {"c~d": 2}}`, string(patched))
+	})
+
+	t.Run("should fail the whole patch on a failed test operation", func(t *testing.T) {
+		_, err := portsmanaging.ApplyJSONPatch(
+			[]byte(`{"a": 1}`),
+			[]byte(`[{"op": "replace", "path": "/a", "value": 2}, {"op": "test", "path": "/a", "value": 1}]`),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail on an out of range array index", func(t *testing.T) {
+		_, err := portsmanaging.ApplyJSONPatch(
+			[]byte(`{"a": [1]}`),
+			[]byte(`[{"op": "add", "path": "/a/2", "value": 2}]`),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail on array indexes which are not plain decimal digits", func(t *testing.T) {
+		for _, token := range []string{"+0", "-0", "01", " 0", ""} {
+			_, err := portsmanaging.ApplyJSONPatch(
+				[]byte(`{"a": [1]}`),
+				[]byte(`[{"op": "replace", "path": "/a/`+token+`", "value": 2}]`),
+			)
+			assert.ErrorContains(t, err, "invalid array index '"+token+"'", token)
+		}
+	})
+}
+
+func TestApplyPatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should reject members unknown to a port", func(t *testing.T) {
+		_, err := portsmanaging.ApplyPatch(
+			&portsmanaging.MaritimePort{ID: "AEDXB"},
+			[]byte(`{"unknown": true}`),
+			portsmanaging.MergePatch,
+		)
+		assert.Error(t, err)
+	})
+}
@@ -1,13 +1,63 @@
 package portsmanaging
 
+import "context"
+
 // PortsStore is a port interface representing operations on portsmanaging.MaritimePort entity.
+// Implementations should give up on long-running operations once ctx is done.
+// Ports passed to and returned by implementations are owned by the caller and may be
+// modified without affecting the stored ports.
 type PortsStore interface {
-	// UpsertPort inserts or modifies a new/existing portsmanaging.MaritimePort entity.
-	UpsertPort(port *MaritimePort) (*MaritimePort, bool, error)
+	// UpsertPort inserts or modifies a new/existing portsmanaging.MaritimePort entity provided
+	// that cond holds for the current version of the entity. Otherwise, it returns an
+	// ErrPreconditionFailed error.
+	UpsertPort(ctx context.Context, port *MaritimePort, cond Precondition) (*MaritimePort, bool, error)
+
+	// UpsertPorts inserts or modifies a batch of ports in order and returns the result of each
+	// of them at the same index. Ports failing to be upserted do not affect the other ports,
+	// unless atomic is set, in which case either all ports are upserted or none of them.
+	UpsertPorts(ctx context.Context, ports []*MaritimePort, atomic bool) ([]*UpsertResult, error)
+
+	// UpdatePort atomically replaces the portsmanaging.MaritimePort identified by ID with the
+	// result of update, which receives the current entity and must not modify it. It returns
+	// an ErrNotFound error if no port with such ID exists.
+	UpdatePort(
+		ctx context.Context,
+		id string,
+		update func(current *MaritimePort) (*MaritimePort, error),
+	) (*MaritimePort, error)
 
 	// GetAllPorts returns all available ports from type portsmanaging.MaritimePort.
-	GetAllPorts() ([]*MaritimePort, error)
+	GetAllPorts(ctx context.Context) ([]*MaritimePort, error)
+
+	// QueryPorts returns all ports matching filter in no particular order.
+	QueryPorts(ctx context.Context, filter PortFilter) ([]*MaritimePort, error)
+
+	// ListPorts returns a page of the ports matching opts.Filter ordered according to opts.
+	ListPorts(ctx context.Context, opts ListOptions) (*PortsPage, error)
+
+	// NearestPorts returns the ports closest to the queried location ordered by ascending distance.
+	NearestPorts(ctx context.Context, query NearestQuery) ([]*PortDistance, error)
+
+	// PortsWithinRadius returns the ports within a radius of the queried location ordered by ascending distance.
+	PortsWithinRadius(ctx context.Context, query RadiusQuery) ([]*PortDistance, error)
+
+	// PortsWithinBox returns the ports inside the bounding box ordered by ID.
+	PortsWithinBox(ctx context.Context, box BoundingBox) ([]*MaritimePort, error)
+
+	// SearchPorts returns the ports best matching a free text query ordered by descending relevance.
+	SearchPorts(ctx context.Context, query SearchQuery) ([]*ScoredPort, error)
+
+	// SuggestPorts returns the ports with a name, alias or UN/LOCODE having a word starting with a prefix.
+	SuggestPorts(ctx context.Context, query SuggestQuery) ([]*PortSuggestion, error)
+
+	// GetPortByID returns n portsmanaging.MaritimePort identified by an available ID
+	// or an ErrNotFound error if no port with such ID exists.
+	GetPortByID(ctx context.Context, id string) (*MaritimePort, error)
+
+	// Revision returns the current revision of all ports in the store.
+	Revision(ctx context.Context) (Revision, error)
 
-	// GetPortByID returns n portsmanaging.MaritimePort identified by an available ID.
-	GetPortByID(id string) (*MaritimePort, error)
+	// DeletePort removes the portsmanaging.MaritimePort identified by ID and reports whether it existed,
+	// provided that cond holds for its current version. Otherwise, it returns an ErrPreconditionFailed error.
+	DeletePort(ctx context.Context, id string, cond Precondition) (bool, error)
 }
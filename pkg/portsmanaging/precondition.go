@@ -0,0 +1,41 @@
+package portsmanaging
+
+// Precondition represents a condition on the current version of a port which must hold
+// for a write to be applied. The zero Precondition always holds.
+type Precondition struct {
+	// IfMatch requires the port to exist in one of the listed versions, unless empty.
+	IfMatch []uint64
+	// MustExist requires the port to exist in any version.
+	MustExist bool
+	// MustNotExist requires the port not to exist, i.e. the write to create it.
+	MustNotExist bool
+}
+
+// Check returns an ErrPreconditionFailed error if the precondition does not hold for
+// the current version of the port with the given ID, which is nil if no such port exists.
+func (c Precondition) Check(id string, current *MaritimePort) error {
+	if current == nil {
+		if c.MustExist || len(c.IfMatch) > 0 {
+			return NewError(ErrPreconditionFailed, "port entry with ID '%s' does not exist", id)
+		}
+
+		return nil
+	}
+
+	if c.MustNotExist {
+		return NewError(ErrPreconditionFailed, "port entry with ID '%s' already exists", id)
+	}
+
+	if len(c.IfMatch) == 0 {
+		return nil
+	}
+
+	for _, v := range c.IfMatch {
+		if v == current.Version {
+			return nil
+		}
+	}
+
+	return NewError(
+		ErrPreconditionFailed, "port entry with ID '%s' has changed, its current version is %d", id, current.Version)
+}
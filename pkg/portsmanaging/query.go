@@ -0,0 +1,275 @@
+package portsmanaging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxListLimit is the maximum number of ports returned in a single page.
+	MaxListLimit = 1000
+	// DefaultListLimit is the number of ports returned in a single page by default.
+	DefaultListLimit = 100
+)
+
+// SortField represents a portsmanaging.MaritimePort field ports can be ordered by.
+type SortField string
+
+const (
+	// SortByID orders ports by their ID.
+	SortByID SortField = "id"
+	// SortByName orders ports by their name.
+	SortByName SortField = "name"
+	// SortByCountry orders ports by their country.
+	SortByCountry SortField = "country"
+	// SortByCity orders ports by their city.
+	SortByCity SortField = "city"
+)
+
+// FieldMatch represents a condition on a single port field. It matches field values
+// equal to Value, case-sensitively, or, when Prefix is set, starting with Value regardless
+// of case as defined by FoldCase. A FieldMatch with an empty Value matches everything.
+type FieldMatch struct {
+	Value  string
+	Prefix bool
+}
+
+// IsSet reports whether the condition restricts the matched values.
+func (m FieldMatch) IsSet() bool {
+	return m.Value != ""
+}
+
+// Matches reports whether v satisfies the condition.
+func (m FieldMatch) Matches(v string) bool {
+	if !m.IsSet() {
+		return true
+	}
+
+	if m.Prefix {
+		return strings.HasPrefix(FoldCase(v), FoldCase(m.Value))
+	}
+
+	return v == m.Value
+}
+
+// MatchesAny reports whether any of vv satisfies the condition.
+func (m FieldMatch) MatchesAny(vv []string) bool {
+	if !m.IsSet() {
+		return true
+	}
+
+	for _, v := range vv {
+		if m.Matches(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PortFilter represents conditions on port fields combined with a logical AND.
+// Conditions on the multi-valued Region, Unloc and Alias fields match a port
+// if any of its values matches.
+type PortFilter struct {
+	Name     FieldMatch
+	Country  FieldMatch
+	City     FieldMatch
+	Province FieldMatch
+	Timezone FieldMatch
+	Region   FieldMatch
+	Unloc    FieldMatch
+	Code     FieldMatch
+	Alias    FieldMatch
+}
+
+// Matches reports whether port p satisfies all conditions of the filter.
+func (f PortFilter) Matches(p *MaritimePort) bool {
+	return f.Name.Matches(p.Name) &&
+		f.Country.Matches(p.Country) &&
+		f.City.Matches(p.City) &&
+		f.Province.Matches(p.Province) &&
+		f.Timezone.Matches(p.Timezone) &&
+		f.Region.MatchesAny(p.Regions) &&
+		f.Unloc.MatchesAny(p.Unlocs) &&
+		f.Code.Matches(p.Code) &&
+		f.Alias.MatchesAny(p.Alias)
+}
+
+// ListOptions represents filtering, pagination and ordering options for listing ports.
+// Pages are either addressed by an Offset or by a Cursor returned with the previous
+// page, but not both. Validate replaces a Limit of 0 with DefaultListLimit, while
+// stores listing ports with a Limit of 0 return all of them.
+type ListOptions struct {
+	Filter PortFilter
+	Limit  int
+	Offset int
+	Cursor string
+	SortBy SortField
+	Desc   bool
+}
+
+// PortsPage represents a single page of ports together with the total number of
+// matching ports and a cursor addressing the next page, empty on the last one.
+type PortsPage struct {
+	Ports      []*MaritimePort
+	Total      int
+	NextCursor string
+}
+
+// cursor is the decoded form of an opaque pagination cursor. It holds the position
+// of the last port of a page in the ordering the page was requested with, so that
+// the following page is stable even if ports are inserted or deleted in between.
+type cursor struct {
+	SortBy SortField `json:"s"`
+	Desc   bool      `json:"d"`
+	Key    string    `json:"k"`
+	ID     string    `json:"id"`
+}
+
+// Validate checks the consistency of the list options and applies the defaults.
+func (o *ListOptions) Validate() error {
+	if o.SortBy == "" {
+		o.SortBy = SortByID
+	}
+
+	switch o.SortBy {
+	case SortByID, SortByName, SortByCountry, SortByCity:
+	default:
+		return NewViolationError("sort", "unsupported sort field '%s'", o.SortBy)
+	}
+
+	if o.Limit == 0 {
+		o.Limit = DefaultListLimit
+	}
+
+	if o.Limit < 0 {
+		return NewViolationError("limit", "limit must not be negative")
+	}
+
+	if o.Limit > MaxListLimit {
+		o.Limit = MaxListLimit
+	}
+
+	if o.Offset < 0 {
+		return NewViolationError("offset", "offset must not be negative")
+	}
+
+	if o.Offset > 0 && o.Cursor != "" {
+		return NewViolationError("cursor", "offset and cursor are mutually exclusive")
+	}
+
+	if o.Cursor != "" {
+		c, err := decodeCursor(o.Cursor)
+		if err != nil {
+			return err
+		}
+
+		if c.SortBy != o.SortBy || c.Desc != o.Desc {
+			return NewViolationError("cursor", "cursor does not match the requested sort order")
+		}
+	}
+
+	return nil
+}
+
+// PaginatePorts orders ports according to opts and returns the requested page.
+// The ordering is total, ties on the sort field being broken by port ID.
+// The passed slice gets reordered in place. The options are expected to have been
+// checked with ListOptions.Validate already.
+func PaginatePorts(ports []*MaritimePort, opts ListOptions) (*PortsPage, error) {
+	sort.Slice(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+
+		return comparePorts(sortKey(a, opts.SortBy), a.ID, sortKey(b, opts.SortBy), b.ID, opts.Desc) < 0
+	})
+
+	start := opts.Offset
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		start = sort.Search(len(ports), func(i int) bool {
+			return comparePorts(sortKey(ports[i], opts.SortBy), ports[i].ID, c.Key, c.ID, opts.Desc) > 0
+		})
+	}
+
+	if start > len(ports) {
+		start = len(ports)
+	}
+
+	end := len(ports)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+
+	page := &PortsPage{
+		Ports: ports[start:end],
+		Total: len(ports),
+	}
+
+	if end < len(ports) && end > 0 {
+		last := ports[end-1]
+		page.NextCursor = encodeCursor(cursor{
+			SortBy: opts.SortBy,
+			Desc:   opts.Desc,
+			Key:    sortKey(last, opts.SortBy),
+			ID:     last.ID,
+		})
+	}
+
+	return page, nil
+}
+
+// comparePorts compares two ports given their sort keys and IDs,
+// returning a negative number if the first one is ordered before the second one.
+func comparePorts(keyA, idA, keyB, idB string, desc bool) int {
+	result := strings.Compare(keyA, keyB)
+	if result == 0 {
+		result = strings.Compare(idA, idB)
+	}
+
+	if desc {
+		return -result
+	}
+
+	return result
+}
+
+func sortKey(p *MaritimePort, field SortField) string {
+	switch field {
+	case SortByName:
+		return strings.ToLower(p.Name)
+	case SortByCountry:
+		return strings.ToLower(p.Country)
+	case SortByCity:
+		return strings.ToLower(p.City)
+	default:
+		return p.ID
+	}
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, NewViolationError("cursor", "invalid cursor '%s'", s)
+	}
+
+	var c cursor
+
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, NewViolationError("cursor", "invalid cursor '%s'", s)
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,68 @@
+package portsmanaging_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+func TestListOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should apply the default page size and sort order", func(t *testing.T) {
+		var opts portsmanaging.ListOptions
+
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, portsmanaging.DefaultListLimit, opts.Limit)
+		assert.Equal(t, portsmanaging.SortByID, opts.SortBy)
+	})
+
+	t.Run("should cap the page size", func(t *testing.T) {
+		opts := portsmanaging.ListOptions{Limit: portsmanaging.MaxListLimit + 1}
+
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, portsmanaging.MaxListLimit, opts.Limit)
+	})
+
+	t.Run("should reject a negative page size", func(t *testing.T) {
+		opts := portsmanaging.ListOptions{Limit: -1}
+
+		err := opts.Validate()
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Equal(t, []portsmanaging.Violation{
+			{Field: "limit", Message: "limit must not be negative"},
+		}, portsmanaging.Violations(err))
+	})
+}
+
+func TestFieldMatchMatches(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should match exact values case-sensitively", func(t *testing.T) {
+		match := portsmanaging.FieldMatch{Value: "Étretat"}
+
+		assert.True(t, match.Matches("Étretat"))
+		assert.False(t, match.Matches("étretat"))
+		assert.False(t, match.Matches("Étretat Port"))
+	})
+
+	t.Run("should match prefixes regardless of case of any character", func(t *testing.T) {
+		assert.True(t, portsmanaging.FieldMatch{Value: "étr", Prefix: true}.Matches("Étretat"))
+		assert.True(t, portsmanaging.FieldMatch{Value: "\u212Aen", Prefix: true}.Matches("Kenya"))
+		assert.True(t, portsmanaging.FieldMatch{Value: "ken", Prefix: true}.Matches("\u212Aenya"))
+		assert.False(t, portsmanaging.FieldMatch{Value: "étt", Prefix: true}.Matches("Étretat"))
+		assert.False(t, portsmanaging.FieldMatch{Value: "Étretats", Prefix: true}.Matches("Étretat"))
+	})
+}
+
+func TestFoldCase(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, portsmanaging.FoldCase("KENYA"), portsmanaging.FoldCase("\u212Aenya"))
+	assert.Equal(t, portsmanaging.FoldCase("ÉTRETAT"), portsmanaging.FoldCase("étretat"))
+	assert.Equal(t, portsmanaging.FoldCase("SASSNITZ"), portsmanaging.FoldCase("saſſnitz"))
+	assert.NotEqual(t, portsmanaging.FoldCase("Étretat"), portsmanaging.FoldCase("Etretat"))
+}
@@ -0,0 +1,20 @@
+package portsmanaging
+
+import "time"
+
+// Revision represents the state of all ports in a PortsStore. Its number increases by 1
+// with every modification of any port.
+type Revision struct {
+	Number     uint64
+	ModifiedAt time.Time
+}
+
+// NewRevision returns the initial Revision of a store created at the given time.
+func NewRevision(createdAt time.Time) Revision {
+	return Revision{ModifiedAt: createdAt}
+}
+
+// Next returns the Revision following a modification at the given time.
+func (r Revision) Next(modifiedAt time.Time) Revision {
+	return Revision{Number: r.Number + 1, ModifiedAt: modifiedAt}
+}
@@ -0,0 +1,106 @@
+package portsmanaging
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxSearchLimit is the maximum number of ports returned by a search.
+	MaxSearchLimit = 100
+	// DefaultSearchLimit is the number of ports returned by a search by default.
+	DefaultSearchLimit = 20
+	// MaxSuggestLimit is the maximum number of suggestions returned for a prefix.
+	MaxSuggestLimit = 50
+	// DefaultSuggestLimit is the number of suggestions returned for a prefix by default.
+	DefaultSuggestLimit = 10
+	// MaxSearchLength is the maximum length of a search text in characters.
+	MaxSearchLength = 200
+	// MaxSearchTerms is the maximum number of terms of a search text.
+	MaxSearchTerms = 10
+)
+
+// SearchQuery represents a free text search over port names, cities, provinces,
+// aliases and UN/LOCODEs returning up to Limit best matching ports.
+type SearchQuery struct {
+	Text  string
+	Limit int
+}
+
+// Validate checks the consistency of the query and applies the defaults.
+func (q *SearchQuery) Validate() error {
+	if n := utf8.RuneCountInString(q.Text); n > MaxSearchLength {
+		return NewViolationError("q", "search query must not be longer than %d characters, got %d", MaxSearchLength, n)
+	}
+
+	terms := Tokenize(q.Text)
+	if len(terms) == 0 {
+		return NewViolationError("q", "search query %q contains no searchable terms", strings.TrimSpace(q.Text))
+	}
+
+	if len(terms) > MaxSearchTerms {
+		return NewViolationError("q", "search query must not contain more than %d terms, got %d",
+			MaxSearchTerms, len(terms))
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultSearchLimit
+	}
+
+	if q.Limit < 0 || q.Limit > MaxSearchLimit {
+		return NewViolationError("limit", "limit must be between 1 and %d", MaxSearchLimit)
+	}
+
+	return nil
+}
+
+// ScoredPort represents a port together with its relevance to a search query.
+// Higher scores denote better matches.
+type ScoredPort struct {
+	Port  *MaritimePort `json:"port"`
+	Score float64       `json:"score"`
+}
+
+// SuggestQuery represents a query for up to Limit ports with a name, alias or UN/LOCODE
+// having a word starting with Prefix.
+type SuggestQuery struct {
+	Prefix string
+	Limit  int
+}
+
+// Validate checks the consistency of the query and applies the defaults.
+func (q *SuggestQuery) Validate() error {
+	if len(Tokenize(q.Prefix)) == 0 {
+		return NewViolationError("prefix", "prefix %q contains no searchable terms", strings.TrimSpace(q.Prefix))
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultSuggestLimit
+	}
+
+	if q.Limit < 0 || q.Limit > MaxSuggestLimit {
+		return NewViolationError("limit", "limit must be between 1 and %d", MaxSuggestLimit)
+	}
+
+	return nil
+}
+
+// PortSuggestion represents a compact port entry suggested while typing.
+type PortSuggestion struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Country string `json:"country"`
+}
+
+// MaxEditDistance returns the number of typos tolerated when matching a search term,
+// growing with its length so that short terms must match exactly.
+func MaxEditDistance(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,48 @@
+package portsmanaging_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+func TestSearchQueryValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should apply the default limit", func(t *testing.T) {
+		query := portsmanaging.SearchQuery{Text: "abu zaby"}
+
+		require.NoError(t, query.Validate())
+		assert.Equal(t, portsmanaging.DefaultSearchLimit, query.Limit)
+	})
+
+	t.Run("should reject a search text which is too long", func(t *testing.T) {
+		query := portsmanaging.SearchQuery{Text: strings.Repeat("é", portsmanaging.MaxSearchLength+1)}
+
+		err := query.Validate()
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Equal(t, []portsmanaging.Violation{
+			{Field: "q", Message: "search query must not be longer than 200 characters, got 201"},
+		}, portsmanaging.Violations(err))
+	})
+
+	t.Run("should accept a search text of the maximum length", func(t *testing.T) {
+		query := portsmanaging.SearchQuery{Text: strings.Repeat("é", portsmanaging.MaxSearchLength)}
+
+		assert.NoError(t, query.Validate())
+	})
+
+	t.Run("should reject a search text with too many terms", func(t *testing.T) {
+		query := portsmanaging.SearchQuery{Text: strings.Repeat("port ", portsmanaging.MaxSearchTerms+1)}
+
+		err := query.Validate()
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Equal(t, []portsmanaging.Violation{
+			{Field: "q", Message: "search query must not contain more than 10 terms, got 11"},
+		}, portsmanaging.Violations(err))
+	})
+}
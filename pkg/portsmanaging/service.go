@@ -1,5 +1,11 @@
 package portsmanaging
 
+import (
+	"context"
+	"io"
+
+	pkgErrors "github.com/pkg/errors"
+)
 
 // Service represents execution of business logic upon portsmanaging.MaritimePort.
 type Service struct {
@@ -14,16 +20,207 @@ func NewService(repository PortsStore) *Service {
 }
 
 // GetAllPorts returns all ports of type portsmanaging.MaritimePort stored in the system.
-func (h *Service) GetAllPorts() ([]*MaritimePort, error) {
-	return h.Repository.GetAllPorts()
+func (h *Service) GetAllPorts(ctx context.Context) ([]*MaritimePort, error) {
+	return h.Repository.GetAllPorts(ctx)
+}
+
+// ListPorts returns a page of ports of type portsmanaging.MaritimePort in a stable order.
+func (h *Service) ListPorts(ctx context.Context, opts ListOptions) (*PortsPage, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return h.Repository.ListPorts(ctx, opts)
+}
+
+// NearestPorts returns the ports closest to a location ordered by ascending great-circle distance.
+func (h *Service) NearestPorts(ctx context.Context, query NearestQuery) ([]*PortDistance, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return h.Repository.NearestPorts(ctx, query)
+}
+
+// PortsWithinRadius returns the ports within a radius of a location ordered by ascending great-circle distance.
+func (h *Service) PortsWithinRadius(ctx context.Context, query RadiusQuery) ([]*PortDistance, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return h.Repository.PortsWithinRadius(ctx, query)
+}
+
+// PortsWithinBox returns the ports inside a bounding box ordered by ID.
+func (h *Service) PortsWithinBox(ctx context.Context, box BoundingBox) ([]*MaritimePort, error) {
+	if err := box.Validate(); err != nil {
+		return nil, err
+	}
+
+	return h.Repository.PortsWithinBox(ctx, box)
+}
+
+// SearchPorts returns the ports best matching a free text query ordered by descending relevance.
+func (h *Service) SearchPorts(ctx context.Context, query SearchQuery) ([]*ScoredPort, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return h.Repository.SearchPorts(ctx, query)
+}
+
+// SuggestPorts returns the ports with a name, alias or UN/LOCODE having a word starting with a prefix.
+func (h *Service) SuggestPorts(ctx context.Context, query SuggestQuery) ([]*PortSuggestion, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return h.Repository.SuggestPorts(ctx, query)
+}
+
+// Revision returns the current revision of all ports stored in the system.
+func (h *Service) Revision(ctx context.Context) (Revision, error) {
+	return h.Repository.Revision(ctx)
 }
 
 // GetPortByID returns a porn given a port ID.
-func (h *Service) GetPortByID(ID string) (*MaritimePort, error) {
-	return h.Repository.GetPortByID(ID)
+func (h *Service) GetPortByID(ctx context.Context, ID string) (*MaritimePort, error) {
+	return h.Repository.GetPortByID(ctx, ID)
 }
 
 // CreateOrUpdatePort add a new port entry of type portsmanaging.MaritimePort or updates an existing one.
-func (h *Service) CreateOrUpdatePort(p *MaritimePort) (*MaritimePort, bool, error) {
-	return h.Repository.UpsertPort(p)
+// It returns an ErrValidation error listing all violations if the port is not valid and an
+// ErrPreconditionFailed error if cond does not hold for the current version of the port.
+func (h *Service) CreateOrUpdatePort(
+	ctx context.Context,
+	p *MaritimePort,
+	cond Precondition,
+) (*MaritimePort, bool, error) {
+	if err := p.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	return h.Repository.UpsertPort(ctx, p, cond)
+}
+
+// UpsertPorts adds or updates a batch of port entries and returns the result of each of them
+// at the same index. Items which could not be decoded fail with their decoding error, invalid
+// ports with an ErrValidation error listing all violations. If atomic is set, either all ports
+// are added or updated or, should any of them fail, none of them, in which case the others
+// are reported as skipped.
+func (h *Service) UpsertPorts(ctx context.Context, items []*BatchItem, atomic bool) ([]*UpsertResult, error) {
+	results := make([]*UpsertResult, len(items))
+	valid := make([]*MaritimePort, 0, len(items))
+	validIdx := make([]int, 0, len(items))
+
+	for i, item := range items {
+		err := item.Err
+		if err == nil {
+			err = item.Port.Validate()
+		}
+
+		if err != nil {
+			results[i] = FailedUpsert(item.Port.ID, err)
+
+			continue
+		}
+
+		valid = append(valid, item.Port)
+		validIdx = append(validIdx, i)
+	}
+
+	if atomic && len(valid) < len(items) {
+		for _, i := range validIdx {
+			results[i] = &UpsertResult{ID: items[i].Port.ID, Status: UpsertSkipped}
+		}
+
+		return results, nil
+	}
+
+	stored, err := h.Repository.UpsertPorts(ctx, valid, atomic)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range validIdx {
+		results[i] = stored[j]
+	}
+
+	return results, nil
+}
+
+// ImportPorts adds or updates the ports read from r in the given format and returns the number
+// of imported ports. The ports are streamed into the store one by one, stopping at the first
+// invalid port with an ErrValidation error, which is also returned for malformed data.
+func (h *Service) ImportPorts(ctx context.Context, r io.Reader, format DataFormat) (int, error) {
+	var (
+		loader = NewJSONLoader(h.Repository)
+		report *LoadReport
+		err    error
+	)
+
+	if format == NDJSON {
+		report, err = loader.LoadNDJSON(ctx, r)
+	} else {
+		report, err = loader.Load(ctx, r)
+	}
+
+	return report.Loaded, err
+}
+
+// ExportPorts writes all ports ordered by ID to w in the given format and returns the
+// number of exported ports.
+func (h *Service) ExportPorts(ctx context.Context, w io.Writer, format DataFormat) (int, error) {
+	exporter := NewJSONExporter(h.Repository)
+
+	if format == NDJSON {
+		return exporter.ExportNDJSON(ctx, w)
+	}
+
+	return exporter.Export(ctx, w)
+}
+
+// PatchPort partially updates an existing port entry given a port ID and a patch document
+// of the given format. It returns an ErrNotFound error if no port with such ID exists, an
+// ErrPreconditionFailed error if cond does not hold for its current version and an
+// ErrValidation error if the patched port is not valid.
+func (h *Service) PatchPort(
+	ctx context.Context,
+	ID string,
+	patch []byte,
+	format PatchFormat,
+	cond Precondition,
+) (*MaritimePort, error) {
+	return h.Repository.UpdatePort(ctx, ID, func(current *MaritimePort) (*MaritimePort, error) {
+		if err := cond.Check(ID, current); err != nil {
+			return nil, err
+		}
+
+		patched, err := ApplyPatch(current, patch, format)
+		if err == nil {
+			err = patched.Validate()
+		}
+
+		if err != nil {
+			return nil, pkgErrors.Wrapf(err, "could not patch port entry with ID '%s'", ID)
+		}
+
+		return patched, nil
+	})
+}
+
+// DeletePort removes a port entry given a port ID. It returns an ErrNotFound error
+// if no port with such ID exists and an ErrPreconditionFailed error if cond does not
+// hold for its current version.
+func (h *Service) DeletePort(ctx context.Context, ID string, cond Precondition) error {
+	deleted, err := h.Repository.DeletePort(ctx, ID, cond)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return NotFoundError(ID)
+	}
+
+	return nil
 }
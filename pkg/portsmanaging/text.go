@@ -0,0 +1,147 @@
+package portsmanaging
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeText lower-cases s and collapses all whitespace runs into single spaces,
+// so that free text values like port names can be compared regardless of formatting.
+func NormalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// FoldCase maps every character of s to a single representative of the characters equal
+// to it under Unicode simple case folding, e.g. "k" and the Kelvin sign U+212A to "K",
+// so that strings.EqualFold(a, b) holds if and only if FoldCase(a) == FoldCase(b).
+func FoldCase(s string) string {
+	return strings.Map(foldRune, s)
+}
+
+// foldRune returns the smallest character equal to r under simple case folding.
+func foldRune(r rune) rune {
+	folded := r
+
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < folded {
+			folded = f
+		}
+	}
+
+	return folded
+}
+
+// foldedRunes maps letters with diacritics to their base Latin letters.
+var foldedRunes = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'ĉ': "c", 'ċ': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g", 'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'ĵ': "j", 'ķ': "k", 'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ŏ': "o", 'ő': "o", 'œ': "oe",
+	'ŕ': "r", 'ŗ': "r", 'ř': "r", 'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s", 'ș': "s", 'ß': "ss",
+	'ţ': "t", 'ť': "t", 'ŧ': "t", 'ț': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w", 'ý': "y", 'ÿ': "y", 'ŷ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// isDiacriticMark reports whether r is a diacritic on its own, either a combining
+// mark or one of the spacing marks found in transliterated names, e.g. the cedilla
+// in "Abu Z¸aby".
+func isDiacriticMark(r rune) bool {
+	switch r {
+	case '¸', '´', '¨', '`', '˛', 'ˇ', '˘', '˙', '˚', '˝', 'ˆ', '˜', '\'', '’':
+		return true
+	}
+
+	return unicode.Is(unicode.Mn, r)
+}
+
+// FoldText lower-cases s, replaces letters with diacritics by their base letters
+// and drops standalone diacritic marks, so that "Abu Z¸aby" and "abu zaby" fold
+// to the same text.
+func FoldText(s string) string {
+	var b strings.Builder
+
+	b.Grow(len(s))
+
+	for _, r := range strings.ToLower(s) {
+		if isDiacriticMark(r) {
+			continue
+		}
+
+		if folded, ok := foldedRunes[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// Tokenize folds s and splits it into tokens of letters and digits, dropping all
+// punctuation, e.g. "Abu Z¸aby [Abu Dhabi]" is split into "abu", "zaby", "abu", "dhabi".
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(FoldText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// EditDistance returns the Levenshtein distance between a and b, or maxDistance+1
+// if it exceeds maxDistance.
+func EditDistance(a string, b string, maxDistance int) int {
+	ra, rb := []rune(a), []rune(b)
+
+	if diff := len(ra) - len(rb); diff > maxDistance || -diff > maxDistance {
+		return maxDistance + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = minInt(rowMin, curr[j])
+		}
+
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+
+		prev, curr = curr, prev
+	}
+
+	if prev[len(rb)] > maxDistance {
+		return maxDistance + 1
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,39 @@
+package portsmanaging_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should fold diacritics and split on punctuation", func(t *testing.T) {
+		assert.Equal(t, []string{"abu", "zaby", "abu", "dhabi"}, portsmanaging.Tokenize("Abu Z¸aby [Abu Dhabi]"))
+		assert.Equal(t, []string{"sao", "tome"}, portsmanaging.Tokenize("São Tomé"))
+		assert.Equal(t, []string{"gdansk"}, portsmanaging.Tokenize("Gdańsk"))
+		assert.Equal(t, []string{"montoir", "de", "bretagne"}, portsmanaging.Tokenize("Montoir-de-Bretagne"))
+	})
+
+	t.Run("should return no tokens for punctuation only", func(t *testing.T) {
+		assert.Empty(t, portsmanaging.Tokenize(" [-] "))
+	})
+}
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should count insertions, deletions and substitutions", func(t *testing.T) {
+		assert.Equal(t, 0, portsmanaging.EditDistance("varna", "varna", 2))
+		assert.Equal(t, 1, portsmanaging.EditDistance("odesos", "odessos", 2))
+		assert.Equal(t, 2, portsmanaging.EditDistance("dubia", "dubai", 2))
+	})
+
+	t.Run("should stop beyond the maximum distance", func(t *testing.T) {
+		assert.Equal(t, 2, portsmanaging.EditDistance("rotterdam", "hamburg", 1))
+		assert.Equal(t, 2, portsmanaging.EditDistance("varna", "va", 1))
+	})
+}
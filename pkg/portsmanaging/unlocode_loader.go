@@ -0,0 +1,317 @@
+package portsmanaging
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// Columns of the UN/LOCODE code list.
+const (
+	unlocodeChange = iota
+	unlocodeCountry
+	unlocodeLocation
+	unlocodeName
+	unlocodeNameWoDiacritics
+	unlocodeSubdivision
+	unlocodeFunction
+	unlocodeStatus
+	unlocodeDate
+	unlocodeIATA
+	unlocodeCoordinates
+	unlocodeRemarks
+	unlocodeColumns
+)
+
+// unlocodeCoordinatesPattern matches coordinates in degrees and minutes, e.g. '4312N 02755E'.
+var unlocodeCoordinatesPattern = regexp.MustCompile(`^(\d{2})(\d{2})([NS]) (\d{3})(\d{2})([EW])$`)
+
+// UNLOCODELoader is a service responsible for loading the UN/LOCODE code list released
+// by UNECE in CSV format.
+type UNLOCODELoader struct {
+	Repository PortsStore
+}
+
+// NewUNLOCODELoader is a constructor function for UNLOCODELoader.
+func NewUNLOCODELoader(repository PortsStore) *UNLOCODELoader {
+	return &UNLOCODELoader{
+		Repository: repository,
+	}
+}
+
+// LoadUNLOCODEFile reads a UN/LOCODE CSV file and delegates loading to Load method.
+func (l *UNLOCODELoader) LoadUNLOCODEFile(ctx context.Context, csvFilePath string) error {
+	dataFilePath, errPath := filepath.Abs(csvFilePath)
+	codeList, errFile := os.Open(dataFilePath)
+
+	if err := errors.Join(errPath, errFile); err != nil {
+		return pkgErrors.Wrapf(err, "cannot access UN/LOCODE data from file %s", dataFilePath)
+	}
+
+	defer codeList.Close()
+
+	if _, err := l.Load(ctx, codeList); err != nil {
+		return pkgErrors.Wrapf(err, "cannot load UN/LOCODE data from file: %s", dataFilePath)
+	}
+
+	return nil
+}
+
+// Load stores the port locations of the UN/LOCODE code list via PortsStore and returns the
+// number of loaded ports. A location is merged into the existing port having its UN/LOCODE,
+// only filling in missing fields, or else creates a new port. Loading stops with a RowError
+// at the first malformed row or invalid port.
+func (l *UNLOCODELoader) Load(ctx context.Context, r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var (
+		countryCode string
+		countryName string
+		loaded      int
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return loaded, pkgErrors.WithStack(err)
+		}
+
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return loaded, nil
+		}
+
+		if err != nil {
+			return loaded, csvError(err)
+		}
+
+		row, _ := cr.FieldPos(0)
+
+		if len(record) < unlocodeColumns {
+			return loaded, &RowError{Row: row, Err: NewError(
+				ErrValidation, "UN/LOCODE entry must have %d columns, got %d", unlocodeColumns, len(record))}
+		}
+
+		// Country rows have no location and a name like '.BULGARIA'.
+		if record[unlocodeLocation] == "" {
+			countryCode = record[unlocodeCountry]
+			countryName = countryTitle(strings.TrimPrefix(record[unlocodeName], "."))
+
+			continue
+		}
+
+		if !isUNLOCODEPort(record) {
+			continue
+		}
+
+		country := record[unlocodeCountry]
+		if country == countryCode {
+			country = countryName
+		}
+
+		p, err := unlocodePort(record, country)
+		if err == nil {
+			err = l.store(ctx, p)
+		}
+
+		if err != nil {
+			return loaded, &RowError{Row: row, Err: err}
+		}
+
+		loaded++
+	}
+}
+
+// store merges p into the existing port having its UN/LOCODE or creates it.
+func (l *UNLOCODELoader) store(ctx context.Context, p *MaritimePort) error {
+	existing, err := l.findByUnloc(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if err = p.Validate(); err != nil {
+			return pkgErrors.Wrapf(err, "invalid port entry with ID '%s'", p.ID)
+		}
+
+		_, _, err = l.Repository.UpsertPort(ctx, p, Precondition{MustNotExist: true})
+
+		return pkgErrors.WithStack(err)
+	}
+
+	_, err = l.Repository.UpdatePort(ctx, existing.ID, func(current *MaritimePort) (*MaritimePort, error) {
+		merged := mergeUNLOCODEPort(current, p)
+		if err := merged.Validate(); err != nil {
+			return nil, pkgErrors.Wrapf(err, "invalid port entry with ID '%s'", merged.ID)
+		}
+
+		return merged, nil
+	})
+
+	return pkgErrors.WithStack(err)
+}
+
+// findByUnloc returns the port identified by unloc or else the first port by ID listing
+// unloc among its UN/LOCODEs. It returns nil if there is no such port.
+func (l *UNLOCODELoader) findByUnloc(ctx context.Context, unloc string) (*MaritimePort, error) {
+	p, err := l.Repository.GetPortByID(ctx, unloc)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return p, err
+	}
+
+	pp, err := l.Repository.QueryPorts(ctx, PortFilter{Unloc: FieldMatch{Value: unloc}})
+	if err != nil || len(pp) == 0 {
+		return nil, err
+	}
+
+	sort.Slice(pp, func(i, j int) bool {
+		return pp[i].ID < pp[j].ID
+	})
+
+	return pp[0], nil
+}
+
+// isUNLOCODEPort reports whether a location is neither marked for deletion nor a reference
+// to another location and has the port function, i.e. '1' as the first function classifier.
+func isUNLOCODEPort(record []string) bool {
+	switch record[unlocodeChange] {
+	case "X", "=":
+		return false
+	}
+
+	return strings.HasPrefix(record[unlocodeFunction], "1")
+}
+
+// unlocodePort maps a location of the UN/LOCODE code list to a MaritimePort.
+func unlocodePort(record []string, country string) (*MaritimePort, error) {
+	id := record[unlocodeCountry] + record[unlocodeLocation]
+
+	coords, err := parseUNLOCODECoordinates(record[unlocodeCoordinates])
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "invalid port entry with ID '%s'", id)
+	}
+
+	return &MaritimePort{
+		ID:          id,
+		Name:        record[unlocodeName],
+		City:        record[unlocodeName],
+		Province:    record[unlocodeSubdivision],
+		Country:     country,
+		Alias:       []string{},
+		Regions:     []string{},
+		Coordinates: coords,
+		Unlocs:      []string{id},
+	}, nil
+}
+
+// mergeUNLOCODEPort fills the missing fields of current from the UN/LOCODE location p and
+// adds its UN/LOCODE to the UN/LOCODEs of current.
+func mergeUNLOCODEPort(current *MaritimePort, p *MaritimePort) *MaritimePort {
+	merged := current.Clone()
+
+	if merged.Name == "" {
+		merged.Name = p.Name
+	}
+
+	if merged.City == "" {
+		merged.City = p.City
+	}
+
+	if merged.Province == "" {
+		merged.Province = p.Province
+	}
+
+	if merged.Country == "" {
+		merged.Country = p.Country
+	}
+
+	if merged.Coordinates == nil {
+		merged.Coordinates = p.Coordinates
+	}
+
+	for _, unloc := range merged.Unlocs {
+		if unloc == p.ID {
+			return merged
+		}
+	}
+
+	merged.Unlocs = append(merged.Unlocs, p.ID)
+
+	return merged
+}
+
+// parseUNLOCODECoordinates converts coordinates in degrees and minutes like '4312N 02755E'
+// to decimal degrees. It returns nil for empty coordinates.
+func parseUNLOCODECoordinates(s string) (*GeoPoint, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	m := unlocodeCoordinatesPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, NewViolationError(
+			"coordinates", "coordinates must be in the 'DDMMN DDDMME' format, got '%s'", s)
+	}
+
+	lat, errLat := degrees(m[1], m[2], m[3] == "S")
+	lon, errLon := degrees(m[4], m[5], m[6] == "W")
+
+	if err := errors.Join(errLat, errLon); err != nil {
+		return nil, NewViolationError("coordinates", "coordinates '%s' are not valid: %v", s, err)
+	}
+
+	return NewGeoPoint(lat, lon), nil
+}
+
+// degrees converts degrees and minutes to decimal degrees.
+func degrees(deg string, minutes string, negative bool) (float64, error) {
+	d, errDeg := strconv.Atoi(deg)
+	m, errMin := strconv.Atoi(minutes)
+
+	if err := errors.Join(errDeg, errMin); err != nil {
+		return 0, err
+	}
+
+	if m >= 60 {
+		return 0, fmt.Errorf("minutes must be less than 60, got %d", m)
+	}
+
+	v := float64(d) + float64(m)/60
+	if negative {
+		v = -v
+	}
+
+	return v, nil
+}
+
+// countryTitle converts an upper case country name like 'BOSNIA AND HERZEGOVINA' to
+// title case like 'Bosnia and Herzegovina'.
+func countryTitle(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+
+	for i, w := range words {
+		switch w {
+		case "and", "of", "the":
+			if i > 0 {
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+
+	return strings.Join(words, " ")
+}
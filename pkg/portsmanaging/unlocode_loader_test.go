@@ -0,0 +1,148 @@
+package portsmanaging_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+)
+
+const unlocodeCodeList = `,"BG",,".BULGARIA",,,,,,,,
+,"BG","BOJ","Burgas","Burgas","02","1234----","AI","0307",,"4230N 02728E",
+,"BG","NES","Nesebar","Nesebar","02","1-------","RL","0901",,"4239N 02744E",
+,"BG","SOF","Sofia","Sofia","22","--34-6--","AI","0307","SOF","4241N 02319E",
+X,"BG","OLD","Old Port","Old Port","03","1-------","XX","0101",,,
+=,"BG","VRN","Varna = Varna","Varna","03","1-------","AI","0307",,,
+,"BG","VAR","Varna","Varna","03","1234----","AI","0307",,"4312N 02755E",
+,"BO",,".BOSNIA AND HERZEGOVINA",,,,,,,,
+,"BO","NEU","Neum","Neum",,"1-------","RL","0307",,"4255N 01736E",
+,"AX",,".ÅLAND ISLANDS",,,,,,,,
+,"AX","MHQ","Mariehamn","Mariehamn",,"1-------","AI","0307",,"6006N 01957E",
+`
+
+func TestUNLOCODELoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should load ports and merge them with existing ones by UN/LOCODE", func(t *testing.T) {
+		ctx := context.Background()
+		portsStore := memory.NewPortsRepository()
+
+		_, _, err := portsStore.UpsertPort(ctx, &portsmanaging.MaritimePort{
+			ID:          "BGVAR",
+			Name:        "Port of Varna",
+			City:        "Varna",
+			Country:     "Bulgaria",
+			Coordinates: portsmanaging.NewGeoPoint(43.2047, 27.9105),
+			Timezone:    "Europe/Sofia",
+			Unlocs:      []string{"BGVAR"},
+		}, portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		_, _, err = portsStore.UpsertPort(ctx, &portsmanaging.MaritimePort{
+			ID:      "BGBUR",
+			Name:    "Burgas",
+			Country: "Bulgaria",
+			Unlocs:  []string{"BGBUR", "BGBOJ"},
+		}, portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		loader := portsmanaging.NewUNLOCODELoader(portsStore)
+
+		loaded, err := loader.Load(ctx, strings.NewReader(unlocodeCodeList))
+		require.NoError(t, err)
+		assert.Equal(t, 5, loaded)
+
+		p, err := portsStore.GetPortByID(ctx, "BGVAR")
+		require.NoError(t, err)
+		assert.Equal(t, "Port of Varna", p.Name)
+		assert.Equal(t, portsmanaging.NewGeoPoint(43.2047, 27.9105), p.Coordinates)
+		assert.Equal(t, "Europe/Sofia", p.Timezone)
+		assert.Equal(t, []string{"BGVAR"}, p.Unlocs)
+
+		p, err = portsStore.GetPortByID(ctx, "BGBUR")
+		require.NoError(t, err)
+		assert.Equal(t, "Burgas", p.City)
+		assert.Equal(t, "02", p.Province)
+		assert.Equal(t, portsmanaging.NewGeoPoint(42.5, 27+28.0/60), p.Coordinates)
+		assert.Equal(t, []string{"BGBUR", "BGBOJ"}, p.Unlocs)
+
+		_, err = portsStore.GetPortByID(ctx, "BGBOJ")
+		require.ErrorIs(t, err, portsmanaging.ErrNotFound)
+
+		p, err = portsStore.GetPortByID(ctx, "BGNES")
+		require.NoError(t, err)
+		assert.Equal(t, &portsmanaging.MaritimePort{
+			Version:     1,
+			ID:          "BGNES",
+			Name:        "Nesebar",
+			City:        "Nesebar",
+			Province:    "02",
+			Country:     "Bulgaria",
+			Alias:       []string{},
+			Regions:     []string{},
+			Coordinates: portsmanaging.NewGeoPoint(42+39.0/60, 27+44.0/60),
+			Unlocs:      []string{"BGNES"},
+		}, p)
+
+		p, err = portsStore.GetPortByID(ctx, "BONEU")
+		require.NoError(t, err)
+		assert.Equal(t, "Bosnia and Herzegovina", p.Country)
+		assert.Empty(t, p.Province)
+
+		p, err = portsStore.GetPortByID(ctx, "AXMHQ")
+		require.NoError(t, err)
+		assert.Equal(t, "Åland Islands", p.Country)
+
+		for _, id := range []string{"BGSOF", "BGOLD", "BGVRN"} {
+			_, err = portsStore.GetPortByID(ctx, id)
+			require.ErrorIs(t, err, portsmanaging.ErrNotFound, id)
+		}
+	})
+
+	t.Run("should convert southern and western coordinates", func(t *testing.T) {
+		portsStore := memory.NewPortsRepository()
+		loader := portsmanaging.NewUNLOCODELoader(portsStore)
+
+		_, err := loader.Load(context.Background(), strings.NewReader(
+			`,"BR",,".BRAZIL",,,,,,,,`+"\n"+
+				`,"BR","SSZ","Santos","Santos","SP","12345---","AI","0307",,"2356S 04619W",`+"\n",
+		))
+		require.NoError(t, err)
+
+		p, err := portsStore.GetPortByID(context.Background(), "BRSSZ")
+		require.NoError(t, err)
+		assert.Equal(t, "Brazil", p.Country)
+		assert.Equal(t, portsmanaging.NewGeoPoint(-(23+56.0/60), -(46+19.0/60)), p.Coordinates)
+	})
+
+	t.Run("should report the row of malformed coordinates", func(t *testing.T) {
+		loader := portsmanaging.NewUNLOCODELoader(memory.NewPortsRepository())
+
+		loaded, err := loader.Load(context.Background(), strings.NewReader(
+			`,"BG",,".BULGARIA",,,,,,,,`+"\n"+
+				`,"BG","VAR","Varna","Varna","03","1234----","AI","0307",,"4312N 02755E",`+"\n"+
+				`,"BG","BOJ","Burgas","Burgas","02","1234----","AI","0307",,"4275N 02728E",`+"\n",
+		))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Equal(t, 1, loaded)
+		assert.EqualError(t, err, "row 3: invalid port entry with ID 'BGBOJ': "+
+			"coordinates '4275N 02728E' are not valid: minutes must be less than 60, got 75")
+
+		var rowErr *portsmanaging.RowError
+		require.ErrorAs(t, err, &rowErr)
+		assert.Equal(t, 3, rowErr.Row)
+	})
+
+	t.Run("should reject rows with missing columns", func(t *testing.T) {
+		loader := portsmanaging.NewUNLOCODELoader(memory.NewPortsRepository())
+
+		_, err := loader.Load(context.Background(), strings.NewReader(`,"BG","VAR","Varna"`+"\n"))
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.EqualError(t, err, "row 1: UN/LOCODE entry must have 12 columns, got 4")
+	})
+}
@@ -0,0 +1,75 @@
+package portsmanaging
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// unlocPattern matches a UN/LOCODE: an ISO 3166-1 alpha-2 country code followed
+// by a 3 character location code made of letters and the digits 2-9.
+var unlocPattern = regexp.MustCompile(`^[A-Z]{2}[A-Z2-9]{3}$`)
+
+// Validate checks that the port is well-formed before it gets stored. The ID and all
+// UN/LOCODEs must be valid UN/LOCODEs, the name and country are required, the coordinates
+// are either unknown or within the latitude and longitude ranges and the timezone is either
+// unknown or an IANA time zone name. All violations found are returned at once in a
+// single ErrValidation error.
+func (p *MaritimePort) Validate() error {
+	var violations []Violation
+
+	violate := func(field string, format string, args ...any) {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !IsUnloc(p.ID) {
+		violate("id", "id must be a valid UN/LOCODE, got '%s'", p.ID)
+	}
+
+	if strings.TrimSpace(p.Name) == "" {
+		violate("name", "name is required")
+	}
+
+	if strings.TrimSpace(p.Country) == "" {
+		violate("country", "country is required")
+	}
+
+	if c := p.Coordinates; c != nil {
+		if math.IsNaN(c.Lon) || c.Lon < -180 || c.Lon > 180 {
+			violate("coordinates", "longitude must be between -180 and 180, got %v", c.Lon)
+		}
+
+		if math.IsNaN(c.Lat) || c.Lat < -90 || c.Lat > 90 {
+			violate("coordinates", "latitude must be between -90 and 90, got %v", c.Lat)
+		}
+	}
+
+	if p.Timezone != "" && !isTimezone(p.Timezone) {
+		violate("timezone", "timezone must be an IANA time zone name, got '%s'", p.Timezone)
+	}
+
+	for i, unloc := range p.Unlocs {
+		if !IsUnloc(unloc) {
+			violate(fmt.Sprintf("unlocs[%d]", i), "unlocs must be valid UN/LOCODEs, got '%s'", unloc)
+		}
+	}
+
+	return NewValidationError(violations)
+}
+
+// IsUnloc reports whether s is a well-formed UN/LOCODE, e.g. "AEDXB".
+func IsUnloc(s string) bool {
+	return unlocPattern.MatchString(s)
+}
+
+func isTimezone(name string) bool {
+	if name == "Local" {
+		return false
+	}
+
+	_, err := time.LoadLocation(name)
+
+	return err == nil
+}
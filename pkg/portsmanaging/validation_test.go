@@ -0,0 +1,61 @@
+package portsmanaging_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+func validPort() *portsmanaging.MaritimePort {
+	return &portsmanaging.MaritimePort{
+		ID:          "AEDXB",
+		Name:        "Dubai",
+		City:        "Dubai",
+		Country:     "United Arab Emirates",
+		Coordinates: portsmanaging.NewGeoPoint(25.25, 55.27),
+		Timezone:    "Asia/Dubai",
+		Unlocs:      []string{"AEDXB"},
+	}
+}
+
+func TestMaritimePortValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should accept a valid port", func(t *testing.T) {
+		assert.NoError(t, validPort().Validate())
+	})
+
+	t.Run("should accept a port with unknown coordinates and timezone", func(t *testing.T) {
+		p := validPort()
+		p.Coordinates = nil
+		p.Timezone = ""
+
+		assert.NoError(t, p.Validate())
+	})
+
+	t.Run("should return all violations at once", func(t *testing.T) {
+		p := validPort()
+		p.ID = "AE-DXB"
+		p.Name = " "
+		p.Country = ""
+		p.Coordinates = portsmanaging.NewGeoPoint(math.NaN(), 185)
+		p.Timezone = "Local"
+		p.Unlocs = []string{"AEDXB", "AED1B"}
+
+		err := p.Validate()
+		require.ErrorIs(t, err, portsmanaging.ErrValidation)
+		assert.Equal(t, []portsmanaging.Violation{
+			{Field: "id", Message: "id must be a valid UN/LOCODE, got 'AE-DXB'"},
+			{Field: "name", Message: "name is required"},
+			{Field: "country", Message: "country is required"},
+			{Field: "coordinates", Message: "longitude must be between -180 and 180, got 185"},
+			{Field: "coordinates", Message: "latitude must be between -90 and 90, got NaN"},
+			{Field: "timezone", Message: "timezone must be an IANA time zone name, got 'Local'"},
+			{Field: "unlocs[1]", Message: "unlocs must be valid UN/LOCODEs, got 'AED1B'"},
+		}, portsmanaging.Violations(err))
+	})
+}
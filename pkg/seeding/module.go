@@ -0,0 +1,16 @@
+package seeding
+
+import (
+	"github.com/powerslider/maritime-ports-service/pkg/configs"
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// InitializeSeeder wires a Seeder loading ports into portsStore as configured.
+func InitializeSeeder(config *configs.Config, portsStore portsmanaging.PortsStore) *Seeder {
+	seeder := NewSeeder(portsStore)
+	seeder.JSONLoader.Lenient = config.Seed.Lenient
+	seeder.JSONLoader.ErrorBudget = config.Seed.ErrorBudget
+	seeder.JSONLoader.Workers = config.Seed.Workers
+
+	return seeder
+}
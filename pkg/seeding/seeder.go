@@ -0,0 +1,127 @@
+package seeding
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	pkgErrors "github.com/pkg/errors"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// Seeder is a service responsible for loading ports data from seed sources.
+type Seeder struct {
+	Repository portsmanaging.PortsStore
+	// JSONLoader loads JSON and NDJSON sources, so that its lenient mode and concurrency
+	// apply to each of them.
+	JSONLoader *portsmanaging.JSONLoader
+	Client     *http.Client
+}
+
+// NewSeeder is a constructor function for Seeder.
+func NewSeeder(repository portsmanaging.PortsStore) *Seeder {
+	return &Seeder{
+		Repository: repository,
+		JSONLoader: portsmanaging.NewJSONLoader(repository),
+		Client:     http.DefaultClient,
+	}
+}
+
+// Seed loads the ports of all seed sources given by URIs in order and returns a report of
+// the loaded ports, whose rejected records name their sources. A URI is either a local path,
+// a file:// URI whose path may be a glob pattern matching several files loaded in lexical
+// order, or an http(s):// URL. Sources are loaded according to their format given by the
+// extension of their path, e.g. '.json', '.ndjson' or '.csv', optionally followed by '.gz'
+// or '.zst' for compressed data, or else by the content type of their HTTP response.
+//
+// The fragment of a URI optionally holds the sha256 checksum of the data, which is then
+// verified before loading any port, and the format overriding the detected one, e.g.
+// 'https://example.com/ports.csv.gz#format=unlocode&sha256=<hex>'. Seeding stops with an
+// error at the first source which cannot be loaded.
+func (s *Seeder) Seed(ctx context.Context, uris []string) (*portsmanaging.LoadReport, error) {
+	report := portsmanaging.NewLoadReport()
+
+	for _, uri := range uris {
+		sources, err := parseSources(uri)
+		if err != nil {
+			return report, err
+		}
+
+		for _, src := range sources {
+			if err = s.seed(ctx, src, report); err != nil {
+				return report, pkgErrors.Wrapf(err, "cannot seed ports from %s", src.name)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// seed loads the ports of a single source and adds them to report.
+func (s *Seeder) seed(ctx context.Context, src *source, report *portsmanaging.LoadReport) error {
+	rc, contentType, err := s.open(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	defer rc.Close()
+
+	format, compression := detect(src, contentType)
+	if format == "" {
+		return fmt.Errorf("unknown format, expected a .json, .ndjson, .jsonl or .csv file")
+	}
+
+	var data io.ReadCloser = rc
+
+	if src.checksum != nil {
+		if data, err = verify(rc, src.checksum); err != nil {
+			return err
+		}
+
+		defer data.Close()
+	}
+
+	zr, err := decompress(data, compression)
+	if err != nil {
+		return err
+	}
+
+	defer zr.Close()
+
+	loaded, err := s.load(ctx, zr, format)
+
+	report.Loaded += loaded.Loaded
+
+	for _, rejected := range loaded.Rejected {
+		rejected.Source = src.name
+		report.Rejected = append(report.Rejected, rejected)
+	}
+
+	return err
+}
+
+// load loads the ports read from r in the given format.
+func (s *Seeder) load(ctx context.Context, r io.Reader, format string) (*portsmanaging.LoadReport, error) {
+	var (
+		loaded int
+		err    error
+	)
+
+	switch format {
+	case FormatJSON:
+		return s.JSONLoader.Load(ctx, r)
+	case FormatNDJSON:
+		return s.JSONLoader.LoadNDJSON(ctx, r)
+	case FormatCSV:
+		loaded, err = portsmanaging.NewCSVLoader(s.Repository).Load(ctx, r)
+	case FormatUNLOCODE:
+		loaded, err = portsmanaging.NewUNLOCODELoader(s.Repository).Load(ctx, r)
+	}
+
+	report := portsmanaging.NewLoadReport()
+	report.Loaded = loaded
+
+	return report, err
+}
@@ -0,0 +1,206 @@
+package seeding_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/seeding"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+)
+
+const (
+	varnaNDJSON = `{"id": "BGVAR", "name": "Varna", "country": "Bulgaria"}` + "\n"
+	burgasCSV   = "id,name,country\nBGBOJ,Burgas,Bulgaria\n"
+	neumCodes   = `,"BA",,".BOSNIA AND HERZEGOVINA",,,,,,,,` + "\n" +
+		`,"BA","NEU","Neum","Neum",,"1-------","RL","0307",,"4255N 01736E",` + "\n"
+)
+
+func TestSeeder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should seed ports from local and compressed files", func(t *testing.T) {
+		dir := t.TempDir()
+		ndjsonPath := writeFile(t, dir, "ports.ndjson.gz", gzipData(t, varnaNDJSON))
+		csvPath := writeFile(t, dir, "ports.csv.zst", zstdData(t, burgasCSV))
+		codesPath := writeFile(t, dir, "codes.csv", []byte(neumCodes))
+
+		portsStore := memory.NewPortsRepository()
+
+		report, err := seeding.NewSeeder(portsStore).Seed(context.Background(), []string{
+			"../../testdata/test_data_ports.json",
+			ndjsonPath,
+			"file://" + csvPath,
+			codesPath + "#format=unlocode",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 6, report.Loaded)
+		assertPorts(t, portsStore, "AEAJM", "AEAUH", "AEDXB", "BANEU", "BGBOJ", "BGVAR")
+	})
+
+	t.Run("should seed ports from all files matching a glob", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "ports-1.ndjson", []byte(varnaNDJSON))
+		writeFile(t, dir, "ports-2.ndjson", []byte(`{"id": "BGBOJ", "name": "Burgas", "country": "Bulgaria"}`))
+		writeFile(t, dir, "ports.csv", []byte(burgasCSV))
+
+		portsStore := memory.NewPortsRepository()
+
+		report, err := seeding.NewSeeder(portsStore).Seed(context.Background(), []string{
+			"file://" + filepath.Join(dir, "ports-*.ndjson"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Loaded)
+		assertPorts(t, portsStore, "BGBOJ", "BGVAR")
+
+		_, err = seeding.NewSeeder(portsStore).Seed(context.Background(), []string{
+			"file://" + filepath.Join(dir, "*.json"),
+		})
+		require.ErrorContains(t, err, "no files match seed source")
+	})
+
+	t.Run("should seed ports from URLs by extension or content type", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/ports", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, err := w.Write([]byte(varnaNDJSON))
+			assert.NoError(t, err)
+		})
+		mux.HandleFunc("/ports.csv.gz", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, err := w.Write(gzipData(t, burgasCSV))
+			assert.NoError(t, err)
+		})
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		portsStore := memory.NewPortsRepository()
+
+		report, err := seeding.NewSeeder(portsStore).Seed(context.Background(), []string{
+			server.URL + "/ports",
+			server.URL + "/ports.csv.gz",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Loaded)
+		assertPorts(t, portsStore, "BGBOJ", "BGVAR")
+
+		_, err = seeding.NewSeeder(portsStore).Seed(context.Background(), []string{server.URL + "/missing.json"})
+		require.EqualError(t, err,
+			"cannot seed ports from "+server.URL+"/missing.json: unexpected response status 404 Not Found")
+	})
+
+	t.Run("should verify checksums before loading", func(t *testing.T) {
+		dir := t.TempDir()
+		data := gzipData(t, varnaNDJSON)
+		dataPath := writeFile(t, dir, "ports.ndjson.gz", data)
+		sum := sha256.Sum256(data)
+
+		portsStore := memory.NewPortsRepository()
+
+		_, err := seeding.NewSeeder(portsStore).Seed(context.Background(), []string{
+			dataPath + "#sha256=" + hex.EncodeToString(make([]byte, sha256.Size)),
+		})
+		require.ErrorIs(t, err, seeding.ErrChecksumMismatch)
+		assertPorts(t, portsStore)
+
+		report, err := seeding.NewSeeder(portsStore).Seed(context.Background(), []string{
+			dataPath + "#sha256=" + hex.EncodeToString(sum[:]),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Loaded)
+		assertPorts(t, portsStore, "BGVAR")
+	})
+
+	t.Run("should report rejected records along with their sources", func(t *testing.T) {
+		dir := t.TempDir()
+		dataPath := writeFile(t, dir, "ports.jsonl", []byte(varnaNDJSON+`{"id": "BGBOJ", "name": "Burgas"}`+"\n"))
+
+		seeder := seeding.NewSeeder(memory.NewPortsRepository())
+		seeder.JSONLoader.Lenient = true
+
+		report, err := seeder.Seed(context.Background(), []string{dataPath})
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Loaded)
+		require.Len(t, report.Rejected, 1)
+		assert.Equal(t, dataPath, report.Rejected[0].Source)
+		assert.Equal(t, "BGBOJ", report.Rejected[0].ID)
+	})
+
+	t.Run("should reject invalid seed sources", func(t *testing.T) {
+		dir := t.TempDir()
+		dataPath := writeFile(t, dir, "ports.txt", []byte(varnaNDJSON))
+
+		for uri, expected := range map[string]string{
+			"ftp://example.com/ports.json":  "unsupported scheme 'ftp' of seed source 'ftp://example.com/ports.json'",
+			"ports.json#format=xml":         "unsupported format 'xml' of seed source 'ports.json#format=xml'",
+			"ports.json#sha256=abc":         "invalid sha256 checksum of seed source 'ports.json#sha256=abc'",
+			"ports.json#md5=abc":            "unknown parameter 'md5' of seed source 'ports.json#md5=abc'",
+			"file://example.com/ports.json": "seed source 'file://example.com/ports.json' must refer to a local file",
+			dataPath: "cannot seed ports from " + dataPath + ": unknown format, " +
+				"expected a .json, .ndjson, .jsonl or .csv file",
+		} {
+			_, err := seeding.NewSeeder(memory.NewPortsRepository()).Seed(context.Background(), []string{uri})
+			require.EqualError(t, err, expected, uri)
+		}
+	})
+}
+
+func writeFile(t *testing.T, dir string, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func zstdData(t *testing.T, data string) []byte {
+	t.Helper()
+
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+
+	defer zw.Close()
+
+	return zw.EncodeAll([]byte(data), nil)
+}
+
+func assertPorts(t *testing.T, portsStore portsmanaging.PortsStore, ids ...string) {
+	t.Helper()
+
+	page, err := portsStore.ListPorts(context.Background(), portsmanaging.ListOptions{})
+	require.NoError(t, err)
+
+	stored := make([]string, 0, len(page.Ports))
+	for _, p := range page.Ports {
+		stored = append(stored, p.ID)
+	}
+
+	assert.ElementsMatch(t, ids, stored)
+}
@@ -0,0 +1,283 @@
+package seeding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	pkgErrors "github.com/pkg/errors"
+)
+
+// Formats of ports data held by seed sources.
+const (
+	// FormatJSON is a JSON object of ports keyed by their IDs, like the ports fixtures.
+	FormatJSON = "json"
+	// FormatNDJSON is newline-delimited JSON with a single port including its ID per line.
+	FormatNDJSON = "ndjson"
+	// FormatCSV is CSV with portsmanaging.DefaultCSVColumns headers.
+	FormatCSV = "csv"
+	// FormatUNLOCODE is the UN/LOCODE code list in CSV released by UNECE.
+	FormatUNLOCODE = "unlocode"
+)
+
+const (
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+)
+
+// ErrChecksumMismatch means that the data of a seed source does not match its checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// source represents a single file or URL holding ports data.
+type source struct {
+	// name identifies the source in messages and reports.
+	name string
+	// path is the path of a local file or of a URL, telling the format by its extension.
+	path     string
+	url      string
+	format   string
+	checksum []byte
+}
+
+// parseSources parses a seed source URI into the sources it refers to. A URI is either
+// a local path, a file:// URI whose path may be a glob pattern matching several files,
+// or an http(s):// URL. Its fragment optionally holds the sha256 checksum of the data
+// and overrides its format, e.g. 'ports.csv#format=unlocode&sha256=<hex>'.
+func parseSources(uri string) ([]*source, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "invalid seed source '%s'", uri)
+	}
+
+	params, err := url.ParseQuery(u.Fragment)
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "invalid parameters of seed source '%s'", uri)
+	}
+
+	src := &source{}
+
+	for key := range params {
+		switch key {
+		case "format":
+			src.format = params.Get(key)
+		case "sha256":
+			src.checksum, err = hex.DecodeString(params.Get(key))
+			if err != nil || len(src.checksum) != sha256.Size {
+				return nil, fmt.Errorf("invalid sha256 checksum of seed source '%s'", uri)
+			}
+		default:
+			return nil, fmt.Errorf("unknown parameter '%s' of seed source '%s'", key, uri)
+		}
+	}
+
+	switch src.format {
+	case "", FormatJSON, FormatNDJSON, FormatCSV, FormatUNLOCODE:
+	default:
+		return nil, fmt.Errorf("unsupported format '%s' of seed source '%s'", src.format, uri)
+	}
+
+	u.Fragment, u.RawFragment = "", ""
+
+	switch u.Scheme {
+	case "":
+		src.name, src.path = u.Path, u.Path
+
+		return []*source{src}, nil
+	case "http", "https":
+		src.name, src.path, src.url = u.String(), u.Path, u.String()
+
+		return []*source{src}, nil
+	case "file":
+		return globSources(src, u, uri)
+	default:
+		return nil, fmt.Errorf("unsupported scheme '%s' of seed source '%s'", u.Scheme, uri)
+	}
+}
+
+// globSources returns a copy of src for every file matching the path of a file:// URI,
+// which is relative if given as an opaque URI like 'file:fixtures/*.json'.
+func globSources(src *source, u *url.URL, uri string) ([]*source, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("seed source '%s' must refer to a local file", uri)
+	}
+
+	pattern := u.Path
+	if u.Opaque != "" {
+		pattern = u.Opaque
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "invalid pattern of seed source '%s'", uri)
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match seed source '%s'", uri)
+	}
+
+	if src.checksum != nil && len(matches) > 1 {
+		return nil, fmt.Errorf("seed source '%s' with a checksum matches %d files", uri, len(matches))
+	}
+
+	sources := make([]*source, 0, len(matches))
+
+	for _, match := range matches {
+		s := *src
+		s.name, s.path = match, match
+		sources = append(sources, &s)
+	}
+
+	return sources, nil
+}
+
+// open opens the data of src and returns it along with its content type, if known.
+func (s *Seeder) open(ctx context.Context, src *source) (io.ReadCloser, string, error) {
+	if src.url == "" {
+		f, err := os.Open(src.path)
+
+		return f, "", pkgErrors.WithStack(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.url, nil)
+	if err != nil {
+		return nil, "", pkgErrors.WithStack(err)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, "", pkgErrors.WithStack(err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		resp.Body.Close()
+
+		return nil, "", fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return resp.Body, resp.Header.Get("Content-Type"), nil
+}
+
+// detect returns the format and the compression of src given by the extensions of its
+// path, e.g. '.ndjson.gz', or else by its content type.
+func detect(src *source, contentType string) (string, string) {
+	var (
+		format      = src.format
+		compression string
+		name        = strings.ToLower(path.Base(filepath.ToSlash(src.path)))
+	)
+
+	switch ext := path.Ext(name); ext {
+	case ".gz":
+		compression, name = compressionGzip, strings.TrimSuffix(name, ext)
+	case ".zst":
+		compression, name = compressionZstd, strings.TrimSuffix(name, ext)
+	}
+
+	if format == "" {
+		switch path.Ext(name) {
+		case ".json":
+			format = FormatJSON
+		case ".ndjson", ".jsonl":
+			format = FormatNDJSON
+		case ".csv":
+			format = FormatCSV
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return format, compression
+	}
+
+	if compression == "" {
+		switch mediaType {
+		case "application/gzip", "application/x-gzip":
+			return format, compressionGzip
+		case "application/zstd":
+			return format, compressionZstd
+		}
+	}
+
+	if format == "" {
+		switch mediaType {
+		case "application/json":
+			format = FormatJSON
+		case "application/x-ndjson", "application/jsonl":
+			format = FormatNDJSON
+		case "text/csv":
+			format = FormatCSV
+		}
+	}
+
+	return format, compression
+}
+
+// verify checks that the data read from r matches checksum. Since ports cannot be loaded
+// before their data is verified, the data is spooled to a temporary file, which is returned
+// for reading it and removed once closed.
+func verify(r io.Reader, checksum []byte) (io.ReadCloser, error) {
+	f, err := os.CreateTemp("", "seed-*")
+	if err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	spooled := &tempFile{File: f}
+	h := sha256.New()
+
+	if _, err = io.Copy(io.MultiWriter(f, h), r); err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		return nil, errors.Join(pkgErrors.WithStack(err), spooled.Close())
+	}
+
+	if sum := h.Sum(nil); !bytes.Equal(sum, checksum) {
+		return nil, errors.Join(pkgErrors.Wrapf(ErrChecksumMismatch, "expected sha256 %x, got %x", checksum, sum),
+			spooled.Close())
+	}
+
+	return spooled, nil
+}
+
+// decompress returns a reader of the data read from r decompressed with compression.
+func decompress(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case compressionGzip:
+		zr, err := gzip.NewReader(r)
+
+		return zr, pkgErrors.WithStack(err)
+	case compressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, pkgErrors.WithStack(err)
+		}
+
+		return zr.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// tempFile is a temporary file, which is removed once closed.
+type tempFile struct {
+	*os.File
+}
+
+// Close closes and removes the file.
+func (f *tempFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.Name()))
+}
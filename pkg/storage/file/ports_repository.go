@@ -0,0 +1,416 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+const (
+	snapshotFileName = "ports.snapshot.json"
+	walFileName      = "ports.wal"
+	seededFileName   = "ports.seeded"
+)
+
+// PortsRepository is a persistent portsmanaging.PortsStore. All reads are served by
+// an in-memory replica, while every write is first made durable in a write-ahead log.
+// Once the log grows past a threshold it gets compacted into a snapshot of the replica.
+type PortsRepository struct {
+	mu                sync.Mutex
+	replica           *memory.PortsRepository
+	wal               *wal
+	snapshotPath      string
+	seededPath        string
+	snapshotThreshold int
+}
+
+// NewPortsRepository is a constructor function for PortsRepository. It recovers
+// the state persisted in dir by loading the latest snapshot and replaying the
+// write-ahead log on top of it. A snapshotThreshold <= 0 disables compaction
+// until the repository is closed.
+func NewPortsRepository(dir string, snapshotThreshold int) (*PortsRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, pkgErrors.Wrapf(err, "cannot create storage directory %s", dir)
+	}
+
+	r := &PortsRepository{
+		replica:           memory.NewPortsRepository(),
+		snapshotPath:      filepath.Join(dir, snapshotFileName),
+		seededPath:        filepath.Join(dir, seededFileName),
+		snapshotThreshold: snapshotThreshold,
+	}
+
+	if err := loadSnapshot(context.Background(), r.snapshotPath, r.replica); err != nil {
+		return nil, err
+	}
+
+	w, err := openWAL(filepath.Join(dir, walFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = w.replay(r.apply); err != nil {
+		_ = w.close()
+
+		return nil, err
+	}
+
+	r.wal = w
+
+	if r.shouldCompact() {
+		if err = r.compact(); err != nil {
+			_ = w.close()
+
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// UpsertPort inserts or modifies a new/existing portsmanaging.MaritimePort entity provided
+// that cond holds for the current version of the entity.
+func (r *PortsRepository) UpsertPort(
+	ctx context.Context,
+	port *portsmanaging.MaritimePort,
+	cond portsmanaging.Precondition,
+) (*portsmanaging.MaritimePort, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, pkgErrors.WithStack(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.check(port.ID, cond); err != nil {
+		return nil, false, err
+	}
+
+	if err := r.wal.append(&walRecord{Op: opUpsert, Port: port}); err != nil {
+		return nil, false, pkgErrors.Wrapf(err, "error: failed to persist port with ID '%s'", port.ID)
+	}
+
+	p, loaded, err := r.replica.UpsertPort(context.Background(), port, portsmanaging.Precondition{})
+	if err != nil {
+		return nil, loaded, err
+	}
+
+	r.compactIfNeeded()
+
+	return p, loaded, nil
+}
+
+// UpsertPorts inserts or modifies a batch of ports and returns the result of each of them
+// at the same index. The whole batch is persisted as a single write-ahead log record, so
+// that an atomic batch is recovered entirely or not at all. Replaying the record yields the
+// same results, therefore failing ports do not need to be filtered out beforehand.
+func (r *PortsRepository) UpsertPorts(
+	ctx context.Context,
+	ports []*portsmanaging.MaritimePort,
+	atomic bool,
+) ([]*portsmanaging.UpsertResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.wal.append(&walRecord{Op: opUpsertBatch, Ports: ports, Atomic: atomic}); err != nil {
+		return nil, pkgErrors.Wrapf(err, "error: failed to persist batch of %d ports", len(ports))
+	}
+
+	results, err := r.replica.UpsertPorts(context.Background(), ports, atomic)
+	if err != nil {
+		return nil, err
+	}
+
+	r.compactIfNeeded()
+
+	return results, nil
+}
+
+// UpdatePort atomically replaces the portsmanaging.MaritimePort identified by ID with the result of update.
+func (r *PortsRepository) UpdatePort(
+	ctx context.Context,
+	id string,
+	update func(current *portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, error),
+) (*portsmanaging.MaritimePort, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.replica.GetPortByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := update(current)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	if err = r.wal.append(&walRecord{Op: opReplace, Port: updated}); err != nil {
+		return nil, pkgErrors.Wrapf(err, "error: failed to persist port with ID '%s'", id)
+	}
+
+	p, err := r.replace(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	r.compactIfNeeded()
+
+	return p, nil
+}
+
+// GetAllPorts returns all available ports from type portsmanaging.MaritimePort.
+func (r *PortsRepository) GetAllPorts(ctx context.Context) ([]*portsmanaging.MaritimePort, error) {
+	return r.replica.GetAllPorts(ctx)
+}
+
+// QueryPorts returns all ports matching filter in no particular order.
+func (r *PortsRepository) QueryPorts(
+	ctx context.Context,
+	filter portsmanaging.PortFilter,
+) ([]*portsmanaging.MaritimePort, error) {
+	return r.replica.QueryPorts(ctx, filter)
+}
+
+// ListPorts returns a page of the ports matching opts.Filter ordered according to opts.
+func (r *PortsRepository) ListPorts(
+	ctx context.Context,
+	opts portsmanaging.ListOptions,
+) (*portsmanaging.PortsPage, error) {
+	return r.replica.ListPorts(ctx, opts)
+}
+
+// NearestPorts returns the ports closest to the queried location ordered by ascending distance.
+func (r *PortsRepository) NearestPorts(
+	ctx context.Context,
+	query portsmanaging.NearestQuery,
+) ([]*portsmanaging.PortDistance, error) {
+	return r.replica.NearestPorts(ctx, query)
+}
+
+// PortsWithinRadius returns the ports within a radius of the queried location ordered by ascending distance.
+func (r *PortsRepository) PortsWithinRadius(
+	ctx context.Context,
+	query portsmanaging.RadiusQuery,
+) ([]*portsmanaging.PortDistance, error) {
+	return r.replica.PortsWithinRadius(ctx, query)
+}
+
+// PortsWithinBox returns the ports inside the bounding box ordered by ID.
+func (r *PortsRepository) PortsWithinBox(
+	ctx context.Context,
+	box portsmanaging.BoundingBox,
+) ([]*portsmanaging.MaritimePort, error) {
+	return r.replica.PortsWithinBox(ctx, box)
+}
+
+// SearchPorts returns the ports best matching a free text query ordered by descending relevance.
+func (r *PortsRepository) SearchPorts(
+	ctx context.Context,
+	query portsmanaging.SearchQuery,
+) ([]*portsmanaging.ScoredPort, error) {
+	return r.replica.SearchPorts(ctx, query)
+}
+
+// SuggestPorts returns the ports with a name, alias or UN/LOCODE having a word starting with a prefix.
+func (r *PortsRepository) SuggestPorts(
+	ctx context.Context,
+	query portsmanaging.SuggestQuery,
+) ([]*portsmanaging.PortSuggestion, error) {
+	return r.replica.SuggestPorts(ctx, query)
+}
+
+// Revision returns the current revision of all ports in the repository.
+func (r *PortsRepository) Revision(ctx context.Context) (portsmanaging.Revision, error) {
+	return r.replica.Revision(ctx)
+}
+
+// GetPortByID returns n portsmanaging.MaritimePort identified by an available ID.
+func (r *PortsRepository) GetPortByID(ctx context.Context, id string) (*portsmanaging.MaritimePort, error) {
+	return r.replica.GetPortByID(ctx, id)
+}
+
+// DeletePort removes the portsmanaging.MaritimePort identified by ID and reports whether it existed,
+// provided that cond holds for its current version.
+func (r *PortsRepository) DeletePort(ctx context.Context, id string, cond portsmanaging.Precondition) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.check(id, cond); err != nil {
+		return false, err
+	}
+
+	if _, err := r.replica.GetPortByID(ctx, id); err != nil {
+		if errors.Is(err, portsmanaging.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if err := r.wal.append(&walRecord{Op: opDelete, ID: id}); err != nil {
+		return false, pkgErrors.Wrapf(err, "error: failed to persist deletion of port with ID '%s'", id)
+	}
+
+	deleted, err := r.replica.DeletePort(context.Background(), id, portsmanaging.Precondition{})
+	if err != nil {
+		return deleted, err
+	}
+
+	r.compactIfNeeded()
+
+	return deleted, nil
+}
+
+// Close compacts any pending write-ahead log records into a final snapshot
+// and releases the log file.
+func (r *PortsRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errCompact error
+
+	if r.wal.records > 0 {
+		errCompact = r.compact()
+	}
+
+	return errors.Join(errCompact, r.wal.close())
+}
+
+// Seeded reports whether the repository has been marked as seeded.
+func (r *PortsRepository) Seeded() (bool, error) {
+	_, err := os.Stat(r.seededPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, pkgErrors.Wrap(err, "cannot check seeded marker")
+	}
+
+	return true, nil
+}
+
+// MarkSeeded durably marks the repository as seeded, so that it is not seeded again
+// on restart even if all of its ports get deleted.
+func (r *PortsRepository) MarkSeeded() error {
+	f, err := os.Create(r.seededPath)
+	if err != nil {
+		return pkgErrors.Wrap(err, "cannot create seeded marker")
+	}
+
+	errSync := f.Sync()
+	if err = errors.Join(errSync, f.Close()); err != nil {
+		return pkgErrors.Wrap(err, "cannot write seeded marker")
+	}
+
+	return syncDir(filepath.Dir(r.seededPath))
+}
+
+// apply replays a single write-ahead log record against the replica.
+func (r *PortsRepository) apply(rec *walRecord) error {
+	switch rec.Op {
+	case opUpsert:
+		if rec.Port == nil {
+			return fmt.Errorf("error: upsert record has no port")
+		}
+
+		_, _, err := r.replica.UpsertPort(context.Background(), rec.Port, portsmanaging.Precondition{})
+
+		return err
+	case opUpsertBatch:
+		_, err := r.replica.UpsertPorts(context.Background(), rec.Ports, rec.Atomic)
+
+		return err
+	case opReplace:
+		if rec.Port == nil {
+			return fmt.Errorf("error: replace record has no port")
+		}
+
+		_, err := r.replace(rec.Port)
+
+		return err
+	case opDelete:
+		_, err := r.replica.DeletePort(context.Background(), rec.ID, portsmanaging.Precondition{})
+
+		return err
+	default:
+		return fmt.Errorf("error: unknown write-ahead log operation '%s'", rec.Op)
+	}
+}
+
+// check checks cond against the current version of the port identified by ID in the
+// replica, before anything gets appended to the write-ahead log. Callers must hold r.mu.
+func (r *PortsRepository) check(id string, cond portsmanaging.Precondition) error {
+	current, err := r.replica.GetPortByID(context.Background(), id)
+	if errors.Is(err, portsmanaging.ErrNotFound) {
+		return cond.Check(id, nil)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return cond.Check(id, current)
+}
+
+// replace replaces a port in the replica. Like all replica writes following a write-ahead
+// log append, it must not be abandoned halfway and therefore ignores the caller's context.
+func (r *PortsRepository) replace(port *portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, error) {
+	return r.replica.UpdatePort(
+		context.Background(),
+		port.ID,
+		func(*portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, error) {
+			return port, nil
+		},
+	)
+}
+
+func (r *PortsRepository) shouldCompact() bool {
+	return r.snapshotThreshold > 0 && r.wal.records >= r.snapshotThreshold
+}
+
+// compactIfNeeded compacts the write-ahead log once it reaches the snapshot threshold.
+// A failed compaction does not affect durability since the log is left untouched,
+// so it is only logged and retried on the next write.
+func (r *PortsRepository) compactIfNeeded() {
+	if !r.shouldCompact() {
+		return
+	}
+
+	if err := r.compact(); err != nil {
+		log.Printf("[Compact] cannot compact ports write-ahead log: %v\n", err)
+	}
+}
+
+// compact writes a snapshot of the replica and truncates the write-ahead log.
+// Callers must hold r.mu.
+func (r *PortsRepository) compact() error {
+	ports, err := r.replica.GetAllPorts(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if err = writeSnapshot(r.snapshotPath, ports, r.replica.DeletedVersions()); err != nil {
+		return err
+	}
+
+	return r.wal.reset()
+}
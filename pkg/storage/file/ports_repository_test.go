@@ -0,0 +1,267 @@
+package file_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/file"
+)
+
+func newPort(id string, city string) *portsmanaging.MaritimePort {
+	return &portsmanaging.MaritimePort{
+		ID:          id,
+		Name:        id,
+		City:        city,
+		Country:     "Bulgaria",
+		Alias:       []string{},
+		Regions:     []string{},
+		Coordinates: portsmanaging.NewGeoPoint(43.2, 27.91),
+		Timezone:    "Europe/Sofia",
+		Unlocs:      []string{id},
+	}
+}
+
+func TestPortsRepositoryPersistence(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should recover upserts from the write-ahead log after reopening", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGBOJ", "Burgas"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		_, exists, err := repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna City"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		// Simulate a crash by reopening without closing, i.e. without a final snapshot.
+		reopened, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		ports, err := reopened.GetAllPorts(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, ports, 2)
+
+		p, err := reopened.GetPortByID(context.Background(), "BGVAR")
+		require.NoError(t, err)
+		assert.Equal(t, "Varna City", p.City)
+	})
+
+	t.Run("should recover deletions from the write-ahead log after reopening", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		deleted, err := repo.DeletePort(context.Background(), "BGVAR", portsmanaging.Precondition{})
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		reopened, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		_, err = reopened.GetPortByID(context.Background(), "BGVAR")
+		require.ErrorIs(t, err, portsmanaging.ErrNotFound)
+	})
+
+	t.Run("should not reuse the versions of a deleted port after compaction", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+			require.NoError(t, err)
+		}
+
+		_, err = repo.DeletePort(context.Background(), "BGVAR", portsmanaging.Precondition{})
+		require.NoError(t, err)
+		require.NoError(t, repo.Close())
+
+		walInfo, err := os.Stat(filepath.Join(dir, "ports.wal"))
+		require.NoError(t, err)
+		assert.Zero(t, walInfo.Size())
+
+		reopened, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		_, err = reopened.GetPortByID(context.Background(), "BGVAR")
+		require.ErrorIs(t, err, portsmanaging.ErrNotFound)
+
+		p, _, err := reopened.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), p.Version)
+	})
+
+	t.Run("should compact the write-ahead log into a snapshot", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := file.NewPortsRepository(dir, 2)
+		require.NoError(t, err)
+
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGBOJ", "Burgas"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		walInfo, err := os.Stat(filepath.Join(dir, "ports.wal"))
+		require.NoError(t, err)
+		assert.Zero(t, walInfo.Size())
+
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGNES", "Nesebar"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		require.NoError(t, repo.Close())
+
+		reopened, err := file.NewPortsRepository(dir, 2)
+		require.NoError(t, err)
+
+		ports, err := reopened.GetAllPorts(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, ports, 3)
+	})
+
+	t.Run("should discard a torn trailing write-ahead log record", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		walFile, err := os.OpenFile(filepath.Join(dir, "ports.wal"), os.O_WRONLY|os.O_APPEND, 0o644)
+		require.NoError(t, err)
+		_, err = walFile.WriteString(`1234abcd	{"op":"upsert","port":{"id":"BGB`)
+		require.NoError(t, err)
+		require.NoError(t, walFile.Close())
+
+		reopened, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		ports, err := reopened.GetAllPorts(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, ports, 1)
+
+		_, _, err = reopened.UpsertPort(context.Background(), newPort("BGBOJ", "Burgas"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		again, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		ports, err = again.GetAllPorts(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, ports, 2)
+	})
+
+	t.Run("should fail on a corrupt record in the middle of the write-ahead log", func(t *testing.T) {
+		dir := t.TempDir()
+
+		err := os.WriteFile(
+			filepath.Join(dir, "ports.wal"),
+			[]byte("00000000\t{\"op\":\"upsert\"}\n00000000\t{\"op\":\"upsert\"}\n"),
+			0o644,
+		)
+		require.NoError(t, err)
+
+		_, err = file.NewPortsRepository(dir, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("should recover batches of upserts from the write-ahead log after reopening", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		results, err := repo.UpsertPorts(context.Background(), []*portsmanaging.MaritimePort{
+			newPort("BGVAR", "Varna"), newPort("BGBOJ", "Burgas"), newPort("BGVAR", "Varna City"),
+		}, true)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, portsmanaging.UpsertUpdated, results[2].Status)
+
+		reopened, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		ports, err := reopened.GetAllPorts(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, ports, 2)
+
+		p, err := reopened.GetPortByID(context.Background(), "BGVAR")
+		require.NoError(t, err)
+		assert.Equal(t, "Varna City", p.City)
+		assert.Equal(t, uint64(2), p.Version)
+	})
+
+	t.Run("should recover port versions from the write-ahead log and snapshots", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := file.NewPortsRepository(dir, 3)
+		require.NoError(t, err)
+
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		// The third write gets compacted into a snapshot.
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGBOJ", "Burgas"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		_, _, err = repo.UpsertPort(
+			context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{IfMatch: []uint64{1}})
+		require.ErrorIs(t, err, portsmanaging.ErrPreconditionFailed)
+
+		reopened, err := file.NewPortsRepository(dir, 3)
+		require.NoError(t, err)
+
+		p, err := reopened.GetPortByID(context.Background(), "BGVAR")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), p.Version)
+
+		p, err = reopened.GetPortByID(context.Background(), "BGBOJ")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), p.Version)
+	})
+
+	t.Run("should remember that it has been seeded after reopening", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		_, _, err = repo.UpsertPort(context.Background(), newPort("BGVAR", "Varna"), portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		seeded, err := repo.Seeded()
+		require.NoError(t, err)
+		assert.False(t, seeded)
+
+		require.NoError(t, repo.MarkSeeded())
+		_, err = repo.DeletePort(context.Background(), "BGVAR", portsmanaging.Precondition{})
+		require.NoError(t, err)
+		require.NoError(t, repo.Close())
+
+		reopened, err := file.NewPortsRepository(dir, 0)
+		require.NoError(t, err)
+
+		seeded, err = reopened.Seeded()
+		require.NoError(t, err)
+		assert.True(t, seeded)
+	})
+}
@@ -0,0 +1,126 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// snapshotPort is a port stored in a snapshot together with its version. A deleted port
+// is stored without data but with its last version, so that its versions are not reused.
+type snapshotPort struct {
+	*portsmanaging.MaritimePort
+	Version uint64 `json:"version,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// writeSnapshot atomically replaces the snapshot at path with the given ports and the last
+// versions of deleted ports. The snapshot has the same shape as the fixture files, i.e. a JSON
+// object keyed by port ID, with the version of every port as an additional member.
+// It is first written and synced to a temporary file which then gets renamed over the
+// previous snapshot, so a crash at any point leaves either the old or the new one intact.
+func writeSnapshot(path string, ports []*portsmanaging.MaritimePort, deleted map[string]uint64) error {
+	portsByID := make(map[string]snapshotPort, len(ports)+len(deleted))
+	for id, version := range deleted {
+		portsByID[id] = snapshotPort{Version: version, Deleted: true}
+	}
+
+	for _, p := range ports {
+		portsByID[p.ID] = snapshotPort{MaritimePort: p, Version: p.Version}
+	}
+
+	data, err := json.Marshal(portsByID)
+	if err != nil {
+		return pkgErrors.Wrap(err, "cannot encode snapshot")
+	}
+
+	tmpPath := path + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return pkgErrors.Wrapf(err, "cannot create snapshot file %s", tmpPath)
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return pkgErrors.Wrapf(err, "cannot write snapshot file %s", tmpPath)
+	}
+
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+
+		return pkgErrors.Wrapf(err, "cannot sync snapshot file %s", tmpPath)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return pkgErrors.WithStack(err)
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return pkgErrors.Wrapf(err, "cannot replace snapshot file %s", path)
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// loadSnapshot restores the ports stored in the snapshot at path into store, keeping
+// their versions, as well as the last versions of deleted ports. A missing snapshot is
+// not an error, it simply means nothing was compacted yet.
+func loadSnapshot(ctx context.Context, path string, store *memory.PortsRepository) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return pkgErrors.Wrapf(err, "cannot read snapshot file %s", path)
+	}
+
+	var portsByID map[string]*snapshotPort
+
+	if err = json.Unmarshal(data, &portsByID); err != nil {
+		return portsmanaging.WithKind(
+			portsmanaging.ErrCorrupt, pkgErrors.Wrapf(err, "cannot decode snapshot file %s", path))
+	}
+
+	for id, sp := range portsByID {
+		if sp.Deleted {
+			store.RestoreDeletedVersion(id, sp.Version)
+
+			continue
+		}
+
+		if sp.MaritimePort == nil {
+			return portsmanaging.NewError(
+				portsmanaging.ErrCorrupt, "snapshot file %s has no data for port entry with ID '%s'", path, id)
+		}
+
+		sp.MaritimePort.ID = id
+		sp.MaritimePort.Version = sp.Version
+
+		if err = store.RestorePort(ctx, sp.MaritimePort); err != nil {
+			return pkgErrors.Wrapf(err, "cannot load snapshot file %s", path)
+		}
+	}
+
+	return nil
+}
+
+// syncDir flushes directory entries so that a preceding rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return pkgErrors.WithStack(err)
+	}
+
+	defer d.Close()
+
+	return pkgErrors.WithStack(d.Sync())
+}
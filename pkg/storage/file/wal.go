@@ -0,0 +1,205 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+const (
+	opUpsert      = "upsert"
+	opUpsertBatch = "upsert_batch"
+	opReplace     = "replace"
+	opDelete      = "delete"
+)
+
+// walRecord represents a single mutation persisted in the write-ahead log.
+type walRecord struct {
+	Op     string                        `json:"op"`
+	ID     string                        `json:"id,omitempty"`
+	Port   *portsmanaging.MaritimePort   `json:"port,omitempty"`
+	Ports  []*portsmanaging.MaritimePort `json:"ports,omitempty"`
+	Atomic bool                          `json:"atomic,omitempty"`
+}
+
+// wal is an append-only write-ahead log. Every record is stored on a separate line
+// prefixed with the hex encoded CRC32 checksum of its JSON payload, so that a record
+// torn by a crash in the middle of a write can be detected and discarded on recovery.
+type wal struct {
+	file    *os.File
+	records int
+}
+
+// openWAL opens or creates the write-ahead log at path and positions it for appending.
+func openWAL(path string) (*wal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "cannot open write-ahead log %s", path)
+	}
+
+	return &wal{
+		file: f,
+	}, nil
+}
+
+// replay feeds every intact record of the log to apply. A corrupt or incomplete
+// trailing record is the result of an interrupted write and gets truncated, while
+// corruption anywhere before the tail is reported as an error.
+func (w *wal) replay(apply func(rec *walRecord) error) error {
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return pkgErrors.WithStack(err)
+	}
+
+	var (
+		reader = bufio.NewReader(w.file)
+		offset int64
+	)
+
+	for {
+		line, errRead := reader.ReadBytes('\n')
+		if errRead != nil && errRead != io.EOF {
+			return pkgErrors.WithStack(errRead)
+		}
+
+		if errRead == io.EOF {
+			// A record without its terminating newline was torn by an interrupted write.
+			break
+		}
+
+		rec, errDecode := decodeWALRecord(line)
+		if errDecode != nil {
+			if isLastRecord(reader) {
+				break
+			}
+
+			return portsmanaging.WithKind(
+				portsmanaging.ErrCorrupt,
+				pkgErrors.Wrapf(errDecode, "write-ahead log is corrupt at offset %d", offset),
+			)
+		}
+
+		if err := apply(rec); err != nil {
+			return pkgErrors.Wrapf(err, "cannot replay write-ahead log record at offset %d", offset)
+		}
+
+		offset += int64(len(line))
+		w.records++
+	}
+
+	return w.truncate(offset)
+}
+
+// append durably writes rec at the end of the log. A record which cannot be written
+// or synced completely is truncated again, so that later records are not appended
+// after a partial one.
+func (w *wal) append(rec *walRecord) error {
+	line, err := encodeWALRecord(rec)
+	if err != nil {
+		return err
+	}
+
+	offset, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return pkgErrors.WithStack(err)
+	}
+
+	if _, err = w.file.Write(line); err != nil {
+		return errors.Join(pkgErrors.Wrap(err, "cannot append to write-ahead log"), w.truncate(offset))
+	}
+
+	if err = w.file.Sync(); err != nil {
+		return errors.Join(pkgErrors.Wrap(err, "cannot sync write-ahead log"), w.truncate(offset))
+	}
+
+	w.records++
+
+	return nil
+}
+
+// truncate discards everything after offset and positions the log there for appending.
+func (w *wal) truncate(offset int64) error {
+	if err := w.file.Truncate(offset); err != nil {
+		return pkgErrors.Wrap(err, "cannot truncate write-ahead log")
+	}
+
+	_, err := w.file.Seek(offset, io.SeekStart)
+
+	return pkgErrors.WithStack(err)
+}
+
+// reset discards all records once they have been compacted into a snapshot.
+func (w *wal) reset() error {
+	if err := w.file.Truncate(0); err != nil {
+		return pkgErrors.Wrap(err, "cannot truncate write-ahead log")
+	}
+
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return pkgErrors.WithStack(err)
+	}
+
+	w.records = 0
+
+	return pkgErrors.WithStack(w.file.Sync())
+}
+
+func (w *wal) close() error {
+	return pkgErrors.WithStack(w.file.Close())
+}
+
+func encodeWALRecord(rec *walRecord) ([]byte, error) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "cannot encode write-ahead log record")
+	}
+
+	line := make([]byte, 0, len(payload)+crc32.Size*2+2)
+	line = append(line, fmt.Sprintf("%08x", crc32.ChecksumIEEE(payload))...)
+	line = append(line, '\t')
+	line = append(line, payload...)
+	line = append(line, '\n')
+
+	return line, nil
+}
+
+func decodeWALRecord(line []byte) (*walRecord, error) {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+
+	checksum, payload, found := bytes.Cut(line, []byte{'\t'})
+	if !found {
+		return nil, fmt.Errorf("malformed record: missing checksum separator")
+	}
+
+	expected, err := strconv.ParseUint(string(checksum), 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("malformed record: invalid checksum %q", checksum)
+	}
+
+	if actual := crc32.ChecksumIEEE(payload); uint64(actual) != expected {
+		return nil, fmt.Errorf("checksum mismatch: expected %08x, got %08x", expected, actual)
+	}
+
+	var rec walRecord
+
+	if err = json.Unmarshal(payload, &rec); err != nil {
+		return nil, pkgErrors.Wrap(err, "malformed record payload")
+	}
+
+	return &rec, nil
+}
+
+// isLastRecord reports whether the reader has no more data after the current record.
+func isLastRecord(r *bufio.Reader) bool {
+	_, err := r.Peek(1)
+
+	return err == io.EOF
+}
@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"strings"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// idSet is a set of port IDs.
+type idSet map[string]struct{}
+
+// index maps a field value to the IDs of the ports having it. Values are additionally
+// kept case-folded in sorted order, so that the values starting with a prefix regardless
+// of case are found by a binary search followed by a scan of the matching values only.
+type index struct {
+	entries   map[string]idSet
+	sorted    *sortedEntries
+	normalize func(string) string
+}
+
+func newIndex(normalize func(string) string) *index {
+	return &index{
+		entries:   make(map[string]idSet),
+		sorted:    newSortedEntries(),
+		normalize: normalize,
+	}
+}
+
+func (i *index) add(value string, id string) {
+	key := i.normalize(value)
+	if key == "" {
+		return
+	}
+
+	ids, ok := i.entries[key]
+	if !ok {
+		ids = make(idSet)
+		i.entries[key] = ids
+	}
+
+	ids[id] = struct{}{}
+	i.sorted.insert(indexEntry{key: portsmanaging.FoldCase(key), id: id})
+}
+
+func (i *index) remove(value string, id string) {
+	key := i.normalize(value)
+
+	ids, ok := i.entries[key]
+	if !ok {
+		return
+	}
+
+	delete(ids, id)
+	i.sorted.delete(indexEntry{key: portsmanaging.FoldCase(key), id: id})
+
+	if len(ids) == 0 {
+		delete(i.entries, key)
+	}
+}
+
+// lookup returns the IDs of the ports which may satisfy match. Prefix matches are
+// resolved by scanning the range of sorted values starting with the prefix.
+func (i *index) lookup(match portsmanaging.FieldMatch) idSet {
+	value := i.normalize(match.Value)
+
+	if !match.Prefix {
+		return i.entries[value]
+	}
+
+	prefix := portsmanaging.FoldCase(value)
+	result := make(idSet)
+
+	i.sorted.ascend(indexEntry{key: prefix}, func(e indexEntry) bool {
+		if !strings.HasPrefix(e.key, prefix) {
+			return false
+		}
+
+		result[e.id] = struct{}{}
+
+		return true
+	})
+
+	return result
+}
+
+// secondaryIndexes holds all indexes maintained over the stored ports.
+type secondaryIndexes struct {
+	country *index
+	unloc   *index
+	code    *index
+	name    *index
+	alias   *index
+	spatial *spatialIndex
+	text    *textIndex
+	prefix  *prefixIndex
+}
+
+func newSecondaryIndexes() *secondaryIndexes {
+	exact := func(s string) string { return s }
+
+	return &secondaryIndexes{
+		country: newIndex(exact),
+		unloc:   newIndex(exact),
+		code:    newIndex(exact),
+		name:    newIndex(portsmanaging.NormalizeText),
+		alias:   newIndex(portsmanaging.NormalizeText),
+		spatial: newSpatialIndex(),
+		text:    newTextIndex(),
+		prefix:  newPrefixIndex(),
+	}
+}
+
+func (s *secondaryIndexes) add(p *portsmanaging.MaritimePort) {
+	s.country.add(p.Country, p.ID)
+	s.code.add(p.Code, p.ID)
+	s.name.add(p.Name, p.ID)
+	s.spatial.add(p)
+	s.text.add(p)
+	s.prefix.add(p)
+
+	for _, u := range p.Unlocs {
+		s.unloc.add(u, p.ID)
+	}
+
+	for _, a := range p.Alias {
+		s.alias.add(a, p.ID)
+	}
+}
+
+func (s *secondaryIndexes) remove(p *portsmanaging.MaritimePort) {
+	s.country.remove(p.Country, p.ID)
+	s.code.remove(p.Code, p.ID)
+	s.name.remove(p.Name, p.ID)
+	s.spatial.remove(p)
+	s.text.remove(p)
+	s.prefix.remove(p)
+
+	for _, u := range p.Unlocs {
+		s.unloc.remove(u, p.ID)
+	}
+
+	for _, a := range p.Alias {
+		s.alias.remove(a, p.ID)
+	}
+}
+
+// candidates returns the IDs of the ports which may satisfy filter, taken from
+// the most selective index applicable to it. The boolean result is false if no
+// index applies, in which case all ports are candidates.
+func (s *secondaryIndexes) candidates(filter portsmanaging.PortFilter) (idSet, bool) {
+	var (
+		result  idSet
+		indexed bool
+	)
+
+	for _, c := range []struct {
+		idx   *index
+		match portsmanaging.FieldMatch
+	}{
+		{s.unloc, filter.Unloc},
+		{s.code, filter.Code},
+		{s.name, filter.Name},
+		{s.alias, filter.Alias},
+		{s.country, filter.Country},
+	} {
+		if !c.match.IsSet() {
+			continue
+		}
+
+		ids := c.idx.lookup(c.match)
+		if !indexed || len(ids) < len(result) {
+			result = ids
+			indexed = true
+		}
+
+		if len(result) == 0 {
+			break
+		}
+	}
+
+	return result, indexed
+}
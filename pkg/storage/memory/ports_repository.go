@@ -1,10 +1,12 @@
 package memory
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
 
@@ -12,65 +14,483 @@ import (
 )
 
 // PortsRepository holds the CRUD db operations for CasinoRoundBet.
+// Writes are serialized by mu so that the secondary indexes and the revision
+// are updated atomically together with the stored ports.
+//
+// Stored ports are never modified in place. Every write stores a new copy of the port,
+// which atomically replaces the previous one, and every read returns copies, so that
+// callers can neither observe a port being modified nor modify a stored port.
+//
+// The last version of every deleted port is kept, so that a port created again with
+// the same ID continues from it instead of reusing the versions of the deleted one.
 type PortsRepository struct {
-	store sync.Map
+	mu       sync.RWMutex
+	store    sync.Map
+	indexes  *secondaryIndexes
+	revision portsmanaging.Revision
+	deleted  map[string]uint64
 }
 
 // NewPortsRepository is a constructor function for PortsRepository.
 func NewPortsRepository() *PortsRepository {
 	return &PortsRepository{
-		store: sync.Map{},
+		store:    sync.Map{},
+		indexes:  newSecondaryIndexes(),
+		revision: portsmanaging.NewRevision(time.Now()),
+		deleted:  make(map[string]uint64),
 	}
 }
 
-// UpsertPort inserts or modifies a new/existing portsmanaging.MaritimePort entity.
-func (r *PortsRepository) UpsertPort(port *portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, bool, error) {
-	p, loaded := r.store.LoadOrStore(port.ID, port)
+// UpsertPort inserts or modifies a new/existing portsmanaging.MaritimePort entity provided
+// that cond holds for the current version of the entity.
+func (r *PortsRepository) UpsertPort(
+	ctx context.Context,
+	port *portsmanaging.MaritimePort,
+	cond portsmanaging.Precondition,
+) (*portsmanaging.MaritimePort, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, pkgErrors.WithStack(err)
+	}
 
-	if loaded {
-		updatePortBytes, errMarshal := json.Marshal(port)
-		errUnmarshal := json.Unmarshal(updatePortBytes, p)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.load(port.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err = cond.Check(port.ID, current); err != nil {
+		return nil, false, err
+	}
+
+	updated, err := r.merge(current, port)
+	if err != nil {
+		return nil, current != nil, err
+	}
+
+	r.put(current, updated)
+
+	return updated.Clone(), current != nil, nil
+}
+
+// UpsertPorts inserts or modifies a batch of ports in order and returns the result of each
+// of them at the same index. If atomic is set, the ports are only stored once all of them
+// have been merged successfully, so that either all of them are upserted or none.
+func (r *PortsRepository) UpsertPorts(
+	ctx context.Context,
+	ports []*portsmanaging.MaritimePort,
+	atomic bool,
+) ([]*portsmanaging.UpsertResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
 
-		if err := errors.Join(errMarshal, errUnmarshal); err != nil {
-			return nil, loaded, pkgErrors.Wrapf(
-				err, "error: failed update of existing port with ID '%s'", port.ID)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]*portsmanaging.UpsertResult, 0, len(ports))
+
+	// The ports of an atomic batch are staged together with the stored ports they replace,
+	// so that later ports with the same ID are merged into the staged ones.
+	staged := make(map[string]*portsmanaging.MaritimePort)
+	replaced := make(map[string]*portsmanaging.MaritimePort)
+	order := make([]string, 0, len(ports))
+
+	for _, port := range ports {
+		current, ok := staged[port.ID]
+		if !ok {
+			var err error
+
+			current, err = r.load(port.ID)
+			if err != nil {
+				results = append(results, portsmanaging.FailedUpsert(port.ID, err))
+
+				continue
+			}
+		}
+
+		updated, err := r.merge(current, port)
+		if err != nil {
+			results = append(results, portsmanaging.FailedUpsert(port.ID, err))
+
+			continue
+		}
+
+		results = append(results, portsmanaging.SucceededUpsert(updated.Clone(), current != nil))
+
+		if !atomic {
+			r.put(current, updated)
+
+			continue
 		}
 
-		updatedPort, ok := p.(*portsmanaging.MaritimePort)
 		if !ok {
-			return nil, loaded, fmt.Errorf("error: updated port entry is corrupt: %s", fmt.Sprint(p))
+			replaced[port.ID] = current
+			order = append(order, port.ID)
+		}
+
+		staged[port.ID] = updated
+	}
+
+	if atomic && !portsmanaging.SkipUpserts(results) {
+		for _, id := range order {
+			r.put(replaced[id], staged[id])
 		}
+	}
+
+	return results, nil
+}
+
+// merge returns the port resulting from upserting port onto current, which is nil if the
+// port does not exist yet. Neither of them is modified. Callers must hold r.mu.
+func (r *PortsRepository) merge(
+	current *portsmanaging.MaritimePort,
+	port *portsmanaging.MaritimePort,
+) (*portsmanaging.MaritimePort, error) {
+	if current == nil {
+		created := port.Clone()
+		created.Version = r.deleted[port.ID] + 1
+
+		return created, nil
+	}
+
+	updated := current.Clone()
+
+	updatePortBytes, errMarshal := json.Marshal(port)
+	errUnmarshal := json.Unmarshal(updatePortBytes, updated)
+
+	if err := errors.Join(errMarshal, errUnmarshal); err != nil {
+		return nil, pkgErrors.Wrapf(
+			err, "error: failed update of existing port with ID '%s'", port.ID)
+	}
+
+	updated.Version = current.Version + 1
+
+	return updated, nil
+}
+
+// UpdatePort atomically replaces the portsmanaging.MaritimePort identified by ID with the result of update.
+func (r *PortsRepository) UpdatePort(
+	ctx context.Context,
+	id string,
+	update func(current *portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, error),
+) (*portsmanaging.MaritimePort, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		return nil, portsmanaging.NotFoundError(id)
+	}
+
+	updated, err := update(current.Clone())
+	if err != nil {
+		return nil, err
+	}
 
-		r.store.Store(updatedPort.ID, updatedPort)
+	updated = updated.Clone()
+	updated.Version = current.Version + 1
+	r.put(current, updated)
 
-		return updatedPort, loaded, nil
+	return updated.Clone(), nil
+}
+
+// put atomically replaces the stored port current, if any, with updated and re-indexes it.
+// Callers must hold r.mu.
+func (r *PortsRepository) put(current *portsmanaging.MaritimePort, updated *portsmanaging.MaritimePort) {
+	r.store.Store(updated.ID, updated)
+
+	if current != nil {
+		r.indexes.remove(current)
 	}
 
-	return p.(*portsmanaging.MaritimePort), loaded, nil
+	r.indexes.add(updated)
+	r.modified()
 }
 
 // GetAllPorts returns all available ports from type portsmanaging.MaritimePort.
-func (r *PortsRepository) GetAllPorts() ([]*portsmanaging.MaritimePort, error) {
+// The iteration stops as soon as ctx is done.
+func (r *PortsRepository) GetAllPorts(ctx context.Context) ([]*portsmanaging.MaritimePort, error) {
+	pp, err := r.scanPorts(ctx, portsmanaging.PortFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	return clonePorts(pp), nil
+}
+
+// ListPorts returns a page of the ports matching opts.Filter ordered according to opts.
+func (r *PortsRepository) ListPorts(
+	ctx context.Context,
+	opts portsmanaging.ListOptions,
+) (*portsmanaging.PortsPage, error) {
+	pp, err := r.queryPorts(ctx, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := portsmanaging.PaginatePorts(pp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Ports = clonePorts(page.Ports)
+
+	return page, nil
+}
+
+// QueryPorts returns all ports matching filter in no particular order. Filters on an
+// indexed field (UN/LOCODE, code, name, alias, country) are answered from the secondary
+// indexes, exact ones by a single lookup and prefix ones by a binary search followed by
+// a scan of the matching values, so that only the ports matching the most selective of
+// them are checked against the whole filter. All other filters require a full scan.
+func (r *PortsRepository) QueryPorts(
+	ctx context.Context,
+	filter portsmanaging.PortFilter,
+) ([]*portsmanaging.MaritimePort, error) {
+	pp, err := r.queryPorts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return clonePorts(pp), nil
+}
+
+// queryPorts is like QueryPorts but returns the stored ports, which must not be modified.
+func (r *PortsRepository) queryPorts(
+	ctx context.Context,
+	filter portsmanaging.PortFilter,
+) ([]*portsmanaging.MaritimePort, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids, indexed := r.indexes.candidates(filter)
+	if !indexed {
+		return r.scanPorts(ctx, filter)
+	}
+
+	pp := make([]*portsmanaging.MaritimePort, 0, len(ids))
+
+	for id := range ids {
+		p, err := r.load(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if p != nil && filter.Matches(p) {
+			pp = append(pp, p)
+		}
+	}
+
+	return pp, nil
+}
+
+func (r *PortsRepository) scanPorts(
+	ctx context.Context,
+	filter portsmanaging.PortFilter,
+) ([]*portsmanaging.MaritimePort, error) {
 	var err error
 
 	pp := make([]*portsmanaging.MaritimePort, 0)
 
 	r.store.Range(func(key, value any) bool {
+		if err = ctx.Err(); err != nil {
+			err = pkgErrors.WithStack(err)
+
+			return false
+		}
+
 		p, ok := value.(*portsmanaging.MaritimePort)
-		if ok {
+		if !ok {
+			err = portsmanaging.NewError(
+				portsmanaging.ErrCorrupt, "error: queried port data is corrupt: %s", fmt.Sprint(value))
+
+			return false
+		}
+
+		if filter.Matches(p) {
 			pp = append(pp, p)
-		} else {
-			err = fmt.Errorf("error: queried port data is corrupt: %s", fmt.Sprint(value))
 		}
 
-		return ok
+		return true
 	})
 
 	return pp, err
 }
 
+// NearestPorts returns the ports closest to the queried location ordered by ascending
+// great-circle distance. Ports without valid coordinates are never returned.
+func (r *PortsRepository) NearestPorts(
+	ctx context.Context,
+	query portsmanaging.NearestQuery,
+) ([]*portsmanaging.PortDistance, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.resolveNeighbours(r.indexes.spatial.nearest(query.Lat, query.Lon, query.K, query.MaxKm))
+}
+
+// PortsWithinRadius returns the ports within a radius of the queried location ordered by
+// ascending great-circle distance. Ports without valid coordinates are never returned.
+func (r *PortsRepository) PortsWithinRadius(
+	ctx context.Context,
+	query portsmanaging.RadiusQuery,
+) ([]*portsmanaging.PortDistance, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.resolveNeighbours(r.indexes.spatial.withinRadius(query.Lat, query.Lon, query.RadiusKm))
+}
+
+// PortsWithinBox returns the ports inside the bounding box ordered by ID.
+// Ports without valid coordinates are never returned.
+func (r *PortsRepository) PortsWithinBox(
+	ctx context.Context,
+	box portsmanaging.BoundingBox,
+) ([]*portsmanaging.MaritimePort, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.indexes.spatial.withinBox(box)
+	result := make([]*portsmanaging.MaritimePort, 0, len(ids))
+
+	for _, id := range ids {
+		p, err := r.load(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if p != nil {
+			result = append(result, p.Clone())
+		}
+	}
+
+	return result, nil
+}
+
+// SearchPorts returns the ports best matching the free text query ordered by descending
+// relevance. Terms are matched accent-insensitively, as prefixes and with tolerance for typos.
+func (r *PortsRepository) SearchPorts(
+	ctx context.Context,
+	query portsmanaging.SearchQuery,
+) ([]*portsmanaging.ScoredPort, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := r.indexes.text.search(query.Text, query.Limit)
+	result := make([]*portsmanaging.ScoredPort, 0, len(matches))
+
+	for _, m := range matches {
+		p, err := r.load(m.id)
+		if err != nil {
+			return nil, err
+		}
+
+		if p != nil {
+			result = append(result, &portsmanaging.ScoredPort{Port: p.Clone(), Score: m.score})
+		}
+	}
+
+	return result, nil
+}
+
+// SuggestPorts returns the ports with a name, alias or UN/LOCODE having a word starting with
+// the queried prefix, ordered by the matching value. Prefixes are matched accent-insensitively.
+func (r *PortsRepository) SuggestPorts(
+	ctx context.Context,
+	query portsmanaging.SuggestQuery,
+) ([]*portsmanaging.PortSuggestion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.indexes.prefix.lookup(query.Prefix, query.Limit)
+	result := make([]*portsmanaging.PortSuggestion, 0, len(ids))
+
+	for _, id := range ids {
+		p, err := r.load(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if p != nil {
+			result = append(result, &portsmanaging.PortSuggestion{ID: p.ID, Name: p.Name, Country: p.Country})
+		}
+	}
+
+	return result, nil
+}
+
+func (r *PortsRepository) resolveNeighbours(neighbours []neighbour) ([]*portsmanaging.PortDistance, error) {
+	result := make([]*portsmanaging.PortDistance, 0, len(neighbours))
+
+	for _, n := range neighbours {
+		p, err := r.load(n.id)
+		if err != nil {
+			return nil, err
+		}
+
+		if p != nil {
+			result = append(result, &portsmanaging.PortDistance{Port: p.Clone(), DistanceKm: n.distanceKm})
+		}
+	}
+
+	return result, nil
+}
+
 // GetPortByID returns n portsmanaging.MaritimePort identified by an available ID.
-func (r *PortsRepository) GetPortByID(id string) (*portsmanaging.MaritimePort, error) {
+func (r *PortsRepository) GetPortByID(ctx context.Context, id string) (*portsmanaging.MaritimePort, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, pkgErrors.WithStack(err)
+	}
+
+	p, err := r.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if p == nil {
+		return nil, portsmanaging.NotFoundError(id)
+	}
+
+	return p.Clone(), nil
+}
+
+// load returns the stored port identified by ID or nil if no such port exists.
+// The stored port must not be modified.
+func (r *PortsRepository) load(id string) (*portsmanaging.MaritimePort, error) {
 	v, loaded := r.store.Load(id)
 	if loaded {
 		p, ok := v.(*portsmanaging.MaritimePort)
@@ -78,9 +498,125 @@ func (r *PortsRepository) GetPortByID(id string) (*portsmanaging.MaritimePort, e
 			return p, nil
 		}
 
-		return nil, fmt.Errorf(
-			"error: queried port data for entry with ID '%s' is corrupt: %s", id, fmt.Sprint(v))
+		return nil, portsmanaging.NewError(
+			portsmanaging.ErrCorrupt, "error: queried port data for entry with ID '%s' is corrupt: %s", id, fmt.Sprint(v))
 	}
 
 	return nil, nil
 }
+
+// DeletePort removes the portsmanaging.MaritimePort identified by ID and reports whether it existed,
+// provided that cond holds for its current version.
+func (r *PortsRepository) DeletePort(ctx context.Context, id string, cond portsmanaging.Precondition) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, pkgErrors.WithStack(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.load(id)
+	if err != nil {
+		return false, err
+	}
+
+	if err = cond.Check(id, current); err != nil {
+		return false, err
+	}
+
+	if current == nil {
+		return false, nil
+	}
+
+	r.store.Delete(id)
+	r.indexes.remove(current)
+	r.deleted[id] = current.Version
+	r.modified()
+
+	return true, nil
+}
+
+// RestorePort stores port as it is, including its version, replacing any port with the
+// same ID. It is meant for recovering previously persisted ports and bypasses preconditions.
+// A port without a version is restored in the version following the last version of a
+// deleted port with the same ID, if any, or else in version 1.
+func (r *PortsRepository) RestorePort(ctx context.Context, port *portsmanaging.MaritimePort) error {
+	if err := ctx.Err(); err != nil {
+		return pkgErrors.WithStack(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.load(port.ID)
+	if err != nil {
+		return err
+	}
+
+	if current != nil {
+		r.indexes.remove(current)
+	}
+
+	restored := port.Clone()
+	if restored.Version == 0 {
+		restored.Version = r.deleted[port.ID] + 1
+	}
+
+	r.store.Store(restored.ID, restored)
+	r.indexes.add(restored)
+	r.modified()
+
+	return nil
+}
+
+// DeletedVersions returns the last version of every deleted port by ID.
+func (r *PortsRepository) DeletedVersions() map[string]uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deleted := make(map[string]uint64, len(r.deleted))
+	for id, version := range r.deleted {
+		deleted[id] = version
+	}
+
+	return deleted
+}
+
+// RestoreDeletedVersion records version as the last version of the deleted port identified
+// by ID, unless a later version has already been recorded. It is meant for recovering
+// previously persisted versions of deleted ports.
+func (r *PortsRepository) RestoreDeletedVersion(id string, version uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if version > r.deleted[id] {
+		r.deleted[id] = version
+	}
+}
+
+// Revision returns the current revision of all ports in the repository.
+func (r *PortsRepository) Revision(ctx context.Context) (portsmanaging.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return portsmanaging.Revision{}, pkgErrors.WithStack(err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.revision, nil
+}
+
+// modified advances the revision after a modification. Callers must hold r.mu.
+func (r *PortsRepository) modified() {
+	r.revision = r.revision.Next(time.Now())
+}
+
+// clonePorts returns copies of the stored ports pp.
+func clonePorts(pp []*portsmanaging.MaritimePort) []*portsmanaging.MaritimePort {
+	result := make([]*portsmanaging.MaritimePort, 0, len(pp))
+	for _, p := range pp {
+		result = append(result, p.Clone())
+	}
+
+	return result
+}
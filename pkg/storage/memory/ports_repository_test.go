@@ -0,0 +1,770 @@
+package memory_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+)
+
+func queryIDs(t *testing.T, repo *memory.PortsRepository, filter portsmanaging.PortFilter) []string {
+	t.Helper()
+
+	pp, err := repo.QueryPorts(context.Background(), filter)
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(pp))
+	for _, p := range pp {
+		ids = append(ids, p.ID)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+func TestPortsRepositorySecondaryIndexes(t *testing.T) {
+	t.Parallel()
+
+	repo := memory.NewPortsRepository()
+	loader := portsmanaging.NewJSONLoader(repo)
+
+	_, err := loader.LoadJSONFile(context.Background(), "../../../testdata/test_data_ports.json")
+	require.NoError(t, err)
+
+	t.Run("should query ports by indexed fields", func(t *testing.T) {
+		assert.Equal(t, []string{"AEAJM", "AEAUH", "AEDXB"}, queryIDs(t, repo, portsmanaging.PortFilter{
+			Country: portsmanaging.FieldMatch{Value: "United Arab Emirates"},
+		}))
+		assert.Equal(t, []string{"AEAUH"}, queryIDs(t, repo, portsmanaging.PortFilter{
+			Unloc: portsmanaging.FieldMatch{Value: "AEAUH"},
+		}))
+		assert.Equal(t, []string{"AEAJM", "AEAUH"}, queryIDs(t, repo, portsmanaging.PortFilter{
+			Name: portsmanaging.FieldMatch{Value: "a", Prefix: true},
+		}))
+		assert.Empty(t, queryIDs(t, repo, portsmanaging.PortFilter{
+			Code: portsmanaging.FieldMatch{Value: "52005"},
+			Name: portsmanaging.FieldMatch{Value: "Ajman"},
+		}))
+	})
+
+	t.Run("should keep indexes up to date on writes", func(t *testing.T) {
+		repo := memory.NewPortsRepository()
+
+		_, _, err := repo.UpsertPort(context.Background(), &portsmanaging.MaritimePort{
+			ID: "BGVAR", Name: "Varna", Country: "Bulgaria", Alias: []string{"Odessos"}, Unlocs: []string{"BGVAR"},
+		}, portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		_, _, err = repo.UpsertPort(context.Background(), &portsmanaging.MaritimePort{
+			ID: "BGVAR", Name: "Varna West", Country: "Bulgaria", Unlocs: []string{"BGVAR"},
+		}, portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		assert.Empty(t, queryIDs(t, repo, portsmanaging.PortFilter{
+			Alias: portsmanaging.FieldMatch{Value: "Odessos"},
+		}))
+		assert.Equal(t, []string{"BGVAR"}, queryIDs(t, repo, portsmanaging.PortFilter{
+			Name: portsmanaging.FieldMatch{Value: "varna w", Prefix: true},
+		}))
+
+		_, err = repo.UpdatePort(context.Background(), "BGVAR", func(current *portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, error) {
+			return &portsmanaging.MaritimePort{ID: current.ID, Name: current.Name, Country: "Romania"}, nil
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, queryIDs(t, repo, portsmanaging.PortFilter{
+			Country: portsmanaging.FieldMatch{Value: "Bulgaria"},
+		}))
+
+		deleted, err := repo.DeletePort(context.Background(), "BGVAR", portsmanaging.Precondition{})
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		assert.Empty(t, queryIDs(t, repo, portsmanaging.PortFilter{
+			Country: portsmanaging.FieldMatch{Value: "Romania"},
+		}))
+	})
+
+	t.Run("should query ports by prefixes of indexed fields among many values", func(t *testing.T) {
+		repo := memory.NewPortsRepository()
+		ports := namedPorts(3000)
+
+		for _, p := range ports {
+			_, _, err := repo.UpsertPort(context.Background(), p, portsmanaging.Precondition{})
+			require.NoError(t, err)
+		}
+
+		_, err := repo.DeletePort(context.Background(), "P1250", portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		names := queryIDs(t, repo, portsmanaging.PortFilter{
+			Name: portsmanaging.FieldMatch{Value: "PORT 1", Prefix: true},
+		})
+		assert.Len(t, names, 999)
+		assert.Equal(t, "P1000", names[0])
+		assert.Equal(t, "P1999", names[len(names)-1])
+
+		unlocs := queryIDs(t, repo, portsmanaging.PortFilter{
+			Unloc: portsmanaging.FieldMatch{Value: "xx12", Prefix: true},
+		})
+		assert.Len(t, unlocs, 99)
+		assert.NotContains(t, unlocs, "P1250")
+	})
+
+	t.Run("should query ports by prefixes regardless of case of any character", func(t *testing.T) {
+		repo := memory.NewPortsRepository()
+
+		for _, p := range []*portsmanaging.MaritimePort{
+			{ID: "KEMBA", Name: "Mombasa", Country: "Kenya"},
+			{ID: "FRETR", Name: "Étretat", Country: "France"},
+		} {
+			_, _, err := repo.UpsertPort(context.Background(), p, portsmanaging.Precondition{})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []string{"KEMBA"}, queryIDs(t, repo, portsmanaging.PortFilter{
+			Country: portsmanaging.FieldMatch{Value: "\u212Aen", Prefix: true},
+		}))
+		assert.Equal(t, []string{"FRETR"}, queryIDs(t, repo, portsmanaging.PortFilter{
+			Name: portsmanaging.FieldMatch{Value: "étr", Prefix: true},
+		}))
+		assert.Empty(t, queryIDs(t, repo, portsmanaging.PortFilter{
+			Name: portsmanaging.FieldMatch{Value: "étretat"},
+		}))
+	})
+}
+
+func searchIDs(t *testing.T, repo *memory.PortsRepository, text string) []string {
+	t.Helper()
+
+	result, err := repo.SearchPorts(context.Background(), portsmanaging.SearchQuery{Text: text, Limit: 10})
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(result))
+	for _, sp := range result {
+		ids = append(ids, sp.Port.ID)
+	}
+
+	return ids
+}
+
+func TestPortsRepositorySearchPorts(t *testing.T) {
+	t.Parallel()
+
+	repo := memory.NewPortsRepository()
+
+	for _, p := range []*portsmanaging.MaritimePort{
+		{ID: "BGVAR", Name: "Varna", City: "Varna", Province: "Varna", Alias: []string{"Odessos"}, Unlocs: []string{"BGVAR"}},
+		{ID: "BGBOJ", Name: "Burgas", City: "Burgas", Province: "Burgas", Unlocs: []string{"BGBOJ"}},
+		{ID: "TRIST", Name: "İstanbul", City: "Istanbul", Province: "İstanbul", Unlocs: []string{"TRIST"}},
+		{ID: "FRMTX", Name: "Port de Montoir", City: "Montoir-de-Bretagne", Province: "Pays de la Loire"},
+		{ID: "DEBRV", Name: "Bremerhaven", City: "Bremerhaven", Province: "Bremen", Unlocs: []string{"DEBRV"}},
+	} {
+		_, _, err := repo.UpsertPort(context.Background(), p, portsmanaging.Precondition{})
+		require.NoError(t, err)
+	}
+
+	t.Run("should match terms regardless of case, diacritics and punctuation", func(t *testing.T) {
+		assert.Equal(t, []string{"TRIST"}, searchIDs(t, repo, "ISTANBUL"))
+		assert.Equal(t, []string{"FRMTX"}, searchIDs(t, repo, "montoir bretagne"))
+		assert.Equal(t, []string{"BGBOJ"}, searchIDs(t, repo, "bgboj"))
+	})
+
+	t.Run("should match prefixes and tolerate typos", func(t *testing.T) {
+		assert.Equal(t, []string{"DEBRV"}, searchIDs(t, repo, "bremerhafen"))
+		assert.Equal(t, []string{"BGVAR"}, searchIDs(t, repo, "odesos"))
+		assert.Equal(t, []string{"BGBOJ"}, searchIDs(t, repo, "burgass"))
+		assert.Equal(t, []string{"TRIST"}, searchIDs(t, repo, "itsanbul"))
+		assert.Equal(t, []string{"DEBRV", "FRMTX"}, searchIDs(t, repo, "bre"))
+	})
+
+	t.Run("should rank ports matching more and better terms first", func(t *testing.T) {
+		result, err := repo.SearchPorts(context.Background(), portsmanaging.SearchQuery{Text: "port montoir bremen", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		assert.Equal(t, "FRMTX", result[0].Port.ID)
+		assert.Equal(t, "DEBRV", result[1].Port.ID)
+		assert.Greater(t, result[0].Score, result[1].Score)
+	})
+
+	t.Run("should keep the text index up to date on writes", func(t *testing.T) {
+		_, err := repo.UpdatePort(context.Background(), "BGVAR", func(current *portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, error) {
+			return &portsmanaging.MaritimePort{ID: current.ID, Name: "Varna West"}, nil
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, searchIDs(t, repo, "odessos"))
+		assert.Empty(t, searchIDs(t, repo, "odesos"))
+		assert.Equal(t, []string{"BGVAR"}, searchIDs(t, repo, "west"))
+	})
+}
+
+func suggestIDs(t *testing.T, repo *memory.PortsRepository, prefix string, limit int) []string {
+	t.Helper()
+
+	result, err := repo.SuggestPorts(context.Background(), portsmanaging.SuggestQuery{Prefix: prefix, Limit: limit})
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(result))
+	for _, s := range result {
+		ids = append(ids, s.ID)
+	}
+
+	return ids
+}
+
+func TestPortsRepositorySuggestPorts(t *testing.T) {
+	t.Parallel()
+
+	repo := memory.NewPortsRepository()
+
+	for _, p := range []*portsmanaging.MaritimePort{
+		{ID: "BGVAR", Name: "Varna", Country: "Bulgaria", Alias: []string{"Odessos"}, Unlocs: []string{"BGVAR"}},
+		{ID: "SEVAR", Name: "Varberg", Country: "Sweden", Unlocs: []string{"SEVAR"}},
+		{ID: "TRIST", Name: "İstanbul", Country: "Turkey", Unlocs: []string{"TRIST"}},
+		{ID: "AEAUH", Name: "Abu Dhabi", Country: "United Arab Emirates", Unlocs: []string{"AEAUH"}},
+	} {
+		_, _, err := repo.UpsertPort(context.Background(), p, portsmanaging.Precondition{})
+		require.NoError(t, err)
+	}
+
+	t.Run("should suggest ports by name, alias and UN/LOCODE prefixes ordered by the matching value", func(t *testing.T) {
+		assert.Equal(t, []string{"SEVAR", "BGVAR"}, suggestIDs(t, repo, "VAR", 10))
+		assert.Equal(t, []string{"SEVAR"}, suggestIDs(t, repo, "var", 1))
+		assert.Equal(t, []string{"BGVAR"}, suggestIDs(t, repo, "odes", 10))
+		assert.Equal(t, []string{"BGVAR"}, suggestIDs(t, repo, "bgv", 10))
+		assert.Equal(t, []string{"TRIST"}, suggestIDs(t, repo, "ist", 10))
+		assert.Equal(t, []string{"AEAUH"}, suggestIDs(t, repo, "dha", 10))
+	})
+
+	t.Run("should return compact suggestions", func(t *testing.T) {
+		result, err := repo.SuggestPorts(context.Background(), portsmanaging.SuggestQuery{Prefix: "abu d", Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []*portsmanaging.PortSuggestion{
+			{ID: "AEAUH", Name: "Abu Dhabi", Country: "United Arab Emirates"},
+		}, result)
+	})
+
+	t.Run("should keep the prefix index up to date on writes", func(t *testing.T) {
+		_, _, err := repo.UpsertPort(
+			context.Background(), &portsmanaging.MaritimePort{ID: "SEVAR", Name: "Warberg"}, portsmanaging.Precondition{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"BGVAR"}, suggestIDs(t, repo, "var", 10))
+		assert.Equal(t, []string{"SEVAR"}, suggestIDs(t, repo, "warb", 10))
+
+		deleted, err := repo.DeletePort(context.Background(), "BGVAR", portsmanaging.Precondition{})
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		assert.Empty(t, suggestIDs(t, repo, "var", 10))
+	})
+
+	t.Run("should keep suggestions ordered across many writes", func(t *testing.T) {
+		repo := memory.NewPortsRepository()
+		ports := namedPorts(3000)
+
+		for _, i := range rand.New(rand.NewSource(1)).Perm(len(ports)) {
+			_, _, err := repo.UpsertPort(context.Background(), ports[i], portsmanaging.Precondition{})
+			require.NoError(t, err)
+		}
+
+		expected := make([]string, 0, len(ports))
+
+		for i, p := range ports {
+			if i%3 == 0 {
+				_, err := repo.DeletePort(context.Background(), p.ID, portsmanaging.Precondition{})
+				require.NoError(t, err)
+			} else if i >= 1000 && i < 2000 {
+				expected = append(expected, p.ID)
+			}
+		}
+
+		assert.Equal(t, expected, suggestIDs(t, repo, "port 1", len(ports)))
+	})
+}
+
+// namedPorts returns n ports named "Port 0000" and so on in the order of their names.
+func namedPorts(n int) []*portsmanaging.MaritimePort {
+	ports := make([]*portsmanaging.MaritimePort, 0, n)
+
+	for i := 0; i < n; i++ {
+		ports = append(ports, &portsmanaging.MaritimePort{
+			ID:      fmt.Sprintf("P%04d", i),
+			Name:    fmt.Sprintf("Port %04d", i),
+			City:    fmt.Sprintf("City %04d", i),
+			Country: "Atlantis",
+			Unlocs:  []string{fmt.Sprintf("XX%04d", i)},
+		})
+	}
+
+	return ports
+}
+
+// BenchmarkPortsRepositoryUpsertPort measures seeding an empty repository, including
+// maintaining its secondary indexes.
+func BenchmarkPortsRepositoryUpsertPort(b *testing.B) {
+	for _, n := range []int{1000, 10000, 50000} {
+		ports := namedPorts(n)
+
+		b.Run(fmt.Sprintf("ports=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				repo := memory.NewPortsRepository()
+
+				for _, p := range ports {
+					_, _, err := repo.UpsertPort(context.Background(), p, portsmanaging.Precondition{})
+					require.NoError(b, err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPortsRepositorySuggestPorts(b *testing.B) {
+	repo := memory.NewPortsRepository()
+	loader := portsmanaging.NewJSONLoader(repo)
+
+	_, err := loader.LoadJSONFile(context.Background(), "../../../fixtures/ports.json")
+	require.NoError(b, err)
+
+	prefixes := []string{"a", "sa", "port", "rot", "new y", "cnsha"}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := repo.SuggestPorts(context.Background(), portsmanaging.SuggestQuery{
+			Prefix: prefixes[i%len(prefixes)],
+			Limit:  portsmanaging.DefaultSuggestLimit,
+		})
+		require.NoError(b, err)
+	}
+}
+
+func TestPortsRepositoryContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	repo, _ := randomPortsRepository(t, rnd, 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("should stop iterating over all ports once the context is done", func(t *testing.T) {
+		_, err := repo.GetAllPorts(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should not modify ports once the context is done", func(t *testing.T) {
+		_, _, err := repo.UpsertPort(
+			ctx, &portsmanaging.MaritimePort{ID: "BGVAR", Name: "Varna"}, portsmanaging.Precondition{})
+		require.ErrorIs(t, err, context.Canceled)
+
+		_, err = repo.GetPortByID(context.Background(), "BGVAR")
+		require.ErrorIs(t, err, portsmanaging.ErrNotFound)
+	})
+}
+
+func TestPortsRepositoryVersions(t *testing.T) {
+	t.Parallel()
+
+	repo := memory.NewPortsRepository()
+	ctx := context.Background()
+
+	t.Run("should start at version 1 and increase with every modification", func(t *testing.T) {
+		p, _, err := repo.UpsertPort(
+			ctx, &portsmanaging.MaritimePort{ID: "BGVAR", Name: "Varna"}, portsmanaging.Precondition{MustNotExist: true})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), p.Version)
+
+		p, _, err = repo.UpsertPort(
+			ctx, &portsmanaging.MaritimePort{ID: "BGVAR", Name: "Varna"}, portsmanaging.Precondition{IfMatch: []uint64{1}})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), p.Version)
+
+		rename := func(current *portsmanaging.MaritimePort) (*portsmanaging.MaritimePort, error) {
+			return &portsmanaging.MaritimePort{ID: current.ID, Name: "Varna West"}, nil
+		}
+
+		p, err = repo.UpdatePort(ctx, "BGVAR", rename)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), p.Version)
+	})
+
+	t.Run("should reject writes whose precondition does not hold", func(t *testing.T) {
+		_, _, err := repo.UpsertPort(
+			ctx, &portsmanaging.MaritimePort{ID: "BGVAR", Name: "Varna"}, portsmanaging.Precondition{IfMatch: []uint64{2}})
+		require.ErrorIs(t, err, portsmanaging.ErrPreconditionFailed)
+
+		_, _, err = repo.UpsertPort(
+			ctx, &portsmanaging.MaritimePort{ID: "BGBOJ", Name: "Burgas"}, portsmanaging.Precondition{MustExist: true})
+		require.ErrorIs(t, err, portsmanaging.ErrPreconditionFailed)
+
+		_, err = repo.DeletePort(ctx, "BGVAR", portsmanaging.Precondition{MustNotExist: true})
+		require.ErrorIs(t, err, portsmanaging.ErrPreconditionFailed)
+
+		p, err := repo.GetPortByID(ctx, "BGVAR")
+		require.NoError(t, err)
+		assert.Equal(t, "Varna West", p.Name)
+		assert.Equal(t, uint64(3), p.Version)
+
+		_, err = repo.GetPortByID(ctx, "BGBOJ")
+		require.ErrorIs(t, err, portsmanaging.ErrNotFound)
+	})
+
+	t.Run("should delete a port whose precondition holds", func(t *testing.T) {
+		deleted, err := repo.DeletePort(ctx, "BGVAR", portsmanaging.Precondition{IfMatch: []uint64{3}})
+		require.NoError(t, err)
+		assert.True(t, deleted)
+	})
+
+	t.Run("should continue from the last version of a deleted port", func(t *testing.T) {
+		_, _, err := repo.UpsertPort(
+			ctx, &portsmanaging.MaritimePort{ID: "BGVAR", Name: "Varna"}, portsmanaging.Precondition{IfMatch: []uint64{3}})
+		require.ErrorIs(t, err, portsmanaging.ErrPreconditionFailed)
+
+		p, _, err := repo.UpsertPort(
+			ctx, &portsmanaging.MaritimePort{ID: "BGVAR", Name: "Varna"}, portsmanaging.Precondition{MustNotExist: true})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), p.Version)
+		assert.Equal(t, map[string]uint64{"BGVAR": 3}, repo.DeletedVersions())
+	})
+}
+
+func randomPortsRepository(
+	t *testing.T,
+	rnd *rand.Rand,
+	n int,
+) (*memory.PortsRepository, []*portsmanaging.MaritimePort) {
+	t.Helper()
+
+	repo := memory.NewPortsRepository()
+	ports := make([]*portsmanaging.MaritimePort, 0, n)
+
+	for i := 0; i < n; i++ {
+		p, _, err := repo.UpsertPort(
+			context.Background(),
+			&portsmanaging.MaritimePort{
+				ID:          fmt.Sprintf("P%04d", i),
+				Coordinates: portsmanaging.NewGeoPoint(rnd.Float64()*180-90, rnd.Float64()*360-180),
+			},
+			portsmanaging.Precondition{},
+		)
+		require.NoError(t, err)
+
+		ports = append(ports, p)
+	}
+
+	return repo, ports
+}
+
+func TestPortsRepositoryUpsertPorts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, atomic := range []bool{false, true} {
+		atomic := atomic
+
+		t.Run(fmt.Sprintf("should upsert ports in order with atomic=%t", atomic), func(t *testing.T) {
+			t.Parallel()
+
+			repo := memory.NewPortsRepository()
+
+			_, _, err := repo.UpsertPort(
+				ctx, &portsmanaging.MaritimePort{ID: "BGVAR", Name: "Varna"}, portsmanaging.Precondition{})
+			require.NoError(t, err)
+
+			results, err := repo.UpsertPorts(ctx, []*portsmanaging.MaritimePort{
+				{ID: "BGBOJ", Name: "Burgas"},
+				{ID: "BGVAR", Name: "Varna", City: "Varna"},
+				{ID: "BGBOJ", Name: "Burgas", City: "Burgas"},
+			}, atomic)
+			require.NoError(t, err)
+			require.Len(t, results, 3)
+
+			statuses := make([]portsmanaging.UpsertStatus, 0, len(results))
+			versions := make([]uint64, 0, len(results))
+
+			for _, res := range results {
+				statuses = append(statuses, res.Status)
+				versions = append(versions, res.Port.Version)
+			}
+
+			assert.Equal(t, []portsmanaging.UpsertStatus{
+				portsmanaging.UpsertCreated, portsmanaging.UpsertUpdated, portsmanaging.UpsertUpdated,
+			}, statuses)
+			assert.Equal(t, []uint64{1, 2, 2}, versions)
+
+			p, err := repo.GetPortByID(ctx, "BGBOJ")
+			require.NoError(t, err)
+			assert.Equal(t, "Burgas", p.Name)
+			assert.Equal(t, "Burgas", p.City)
+
+			cityFilter := portsmanaging.PortFilter{City: portsmanaging.FieldMatch{Value: "Burgas"}}
+			assert.Equal(t, []string{"BGBOJ"}, queryIDs(t, repo, cityFilter))
+		})
+	}
+}
+
+func TestPortsRepositoryDefensiveCopies(t *testing.T) {
+	t.Parallel()
+
+	repo := memory.NewPortsRepository()
+	ctx := context.Background()
+
+	input := &portsmanaging.MaritimePort{
+		ID:          "BGVAR",
+		Name:        "Varna",
+		Alias:       []string{"Varna West"},
+		Coordinates: portsmanaging.NewGeoPoint(43.2, 27.91),
+	}
+
+	created, _, err := repo.UpsertPort(ctx, input, portsmanaging.Precondition{})
+	require.NoError(t, err)
+
+	input.Name = "Input"
+	input.Alias[0] = "Input"
+	input.Coordinates.Lat = 0
+	created.Name = "Created"
+	created.Alias[0] = "Created"
+
+	fetched, err := repo.GetPortByID(ctx, "BGVAR")
+	require.NoError(t, err)
+
+	fetched.Coordinates.Lon = 0
+
+	all, err := repo.GetAllPorts(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+
+	all[0].Alias = append(all[0].Alias[:0], "All")
+
+	p, err := repo.GetPortByID(ctx, "BGVAR")
+	require.NoError(t, err)
+	assert.Equal(t, "Varna", p.Name)
+	assert.Equal(t, []string{"Varna West"}, p.Alias)
+	assert.Equal(t, portsmanaging.NewGeoPoint(43.2, 27.91), p.Coordinates)
+	assert.Equal(t, uint64(0), input.Version)
+}
+
+func TestPortsRepositoryConcurrentAccess(t *testing.T) {
+	t.Parallel()
+
+	const (
+		ports   = 20
+		writers = 4
+		readers = 8
+		rounds  = 200
+	)
+
+	repo := memory.NewPortsRepository()
+	ctx := context.Background()
+
+	newPort := func(i int, round int) *portsmanaging.MaritimePort {
+		return &portsmanaging.MaritimePort{
+			ID:          fmt.Sprintf("P%04d", i),
+			Name:        fmt.Sprintf("Port %d", round),
+			Alias:       []string{fmt.Sprintf("Alias %d", round)},
+			Coordinates: portsmanaging.NewGeoPoint(float64(i), float64(round%180)),
+			Unlocs:      []string{fmt.Sprintf("P%04d", i)},
+		}
+	}
+
+	for i := 0; i < ports; i++ {
+		_, _, err := repo.UpsertPort(ctx, newPort(i, 0), portsmanaging.Precondition{})
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for round := 1; round <= rounds; round++ {
+				_, _, err := repo.UpsertPort(ctx, newPort((w+round)%ports, round), portsmanaging.Precondition{})
+				assert.NoError(t, err)
+			}
+		}(w)
+	}
+
+	for rd := 0; rd < readers; rd++ {
+		wg.Add(1)
+
+		go func(rd int) {
+			defer wg.Done()
+
+			for round := 0; round < rounds; round++ {
+				p, err := repo.GetPortByID(ctx, fmt.Sprintf("P%04d", (rd+round)%ports))
+				assert.NoError(t, err)
+
+				// Returned ports are owned by the caller.
+				p.Alias[0] = "Modified"
+
+				all, err := repo.GetAllPorts(ctx)
+				assert.NoError(t, err)
+
+				_, err = json.Marshal(all)
+				assert.NoError(t, err)
+
+				_, err = repo.NearestPorts(ctx, portsmanaging.NearestQuery{Lat: 0, Lon: 0, K: 5})
+				assert.NoError(t, err)
+			}
+		}(rd)
+	}
+
+	wg.Wait()
+
+	all, err := repo.GetAllPorts(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, ports)
+
+	for _, p := range all {
+		assert.NotEqual(t, "Modified", p.Alias[0])
+	}
+}
+
+func TestPortsRepositoryRevision(t *testing.T) {
+	t.Parallel()
+
+	repo := memory.NewPortsRepository()
+	ctx := context.Background()
+
+	initial, err := repo.Revision(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, initial.Number)
+	assert.False(t, initial.ModifiedAt.IsZero())
+
+	_, _, err = repo.UpsertPort(ctx, &portsmanaging.MaritimePort{ID: "BGVAR", Name: "Varna"}, portsmanaging.Precondition{})
+	require.NoError(t, err)
+
+	_, _, err = repo.UpsertPort(
+		ctx, &portsmanaging.MaritimePort{ID: "BGBOJ", Name: "Burgas"}, portsmanaging.Precondition{MustExist: true})
+	require.ErrorIs(t, err, portsmanaging.ErrPreconditionFailed)
+
+	deleted, err := repo.DeletePort(ctx, "BGBOJ", portsmanaging.Precondition{})
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	rev, err := repo.Revision(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), rev.Number)
+	assert.False(t, rev.ModifiedAt.Before(initial.ModifiedAt))
+
+	deleted, err = repo.DeletePort(ctx, "BGVAR", portsmanaging.Precondition{})
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	rev, err = repo.Revision(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), rev.Number)
+}
+
+func TestPortsRepositoryNearestPorts(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(42))
+	repo, ports := randomPortsRepository(t, rnd, 2000)
+
+	t.Run("should match a brute force search including near the poles and the antimeridian", func(t *testing.T) {
+		queries := [][2]float64{{89.9, 0}, {-89.5, 120}, {0, 179.99}, {10, -180}, {45, 45}}
+		for i := 0; i < 50; i++ {
+			queries = append(queries, [2]float64{rnd.Float64()*180 - 90, rnd.Float64()*360 - 180})
+		}
+
+		for _, q := range queries {
+			expected := make([]*portsmanaging.PortDistance, 0, len(ports))
+			for _, p := range ports {
+				d := portsmanaging.HaversineKm(q[0], q[1], p.Coordinates.Lat, p.Coordinates.Lon)
+				expected = append(expected, &portsmanaging.PortDistance{Port: p, DistanceKm: d})
+			}
+
+			sort.Slice(expected, func(i, j int) bool {
+				return expected[i].DistanceKm < expected[j].DistanceKm
+			})
+
+			actual, err := repo.NearestPorts(context.Background(), portsmanaging.NearestQuery{Lat: q[0], Lon: q[1], K: 5})
+			require.NoError(t, err)
+			assert.Equal(t, expected[:5], actual, "query %v", q)
+		}
+	})
+
+	t.Run("should not return ports farther than the maximum distance", func(t *testing.T) {
+		actual, err := repo.NearestPorts(context.Background(), portsmanaging.NearestQuery{Lat: 0, Lon: 0, K: 100, MaxKm: 500})
+		require.NoError(t, err)
+
+		for _, pd := range actual {
+			assert.LessOrEqual(t, pd.DistanceKm, 500.0)
+		}
+	})
+}
+
+func TestPortsRepositoryPortsWithin(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(7))
+	repo, ports := randomPortsRepository(t, rnd, 2000)
+
+	t.Run("should return the ports inside bounding boxes including ones crossing the antimeridian", func(t *testing.T) {
+		for _, box := range []portsmanaging.BoundingBox{
+			{MinLon: -10, MinLat: 35, MaxLon: 30, MaxLat: 60},
+			{MinLon: 170, MinLat: -50, MaxLon: -170, MaxLat: 10},
+			{MinLon: -180, MinLat: -90, MaxLon: 180, MaxLat: 90},
+		} {
+			expected := make([]string, 0)
+			for _, p := range ports {
+				if box.Contains(p.Coordinates.Lat, p.Coordinates.Lon) {
+					expected = append(expected, p.ID)
+				}
+			}
+
+			sort.Strings(expected)
+
+			actual, err := repo.PortsWithinBox(context.Background(), box)
+			require.NoError(t, err)
+
+			actualIDs := make([]string, 0, len(actual))
+			for _, p := range actual {
+				actualIDs = append(actualIDs, p.ID)
+			}
+
+			assert.NotEmpty(t, expected)
+			assert.Equal(t, expected, actualIDs, "box %v", box)
+		}
+	})
+
+	t.Run("should return the ports within a radius ordered by distance", func(t *testing.T) {
+		for _, q := range []portsmanaging.RadiusQuery{
+			{Lat: 43.2, Lon: 27.9, RadiusKm: 1500},
+			{Lat: 0, Lon: 180, RadiusKm: 2000},
+			{Lat: -90, Lon: 0, RadiusKm: 3000},
+		} {
+			expected := 0
+			for _, p := range ports {
+				if portsmanaging.HaversineKm(q.Lat, q.Lon, p.Coordinates.Lat, p.Coordinates.Lon) <= q.RadiusKm {
+					expected++
+				}
+			}
+
+			actual, err := repo.PortsWithinRadius(context.Background(), q)
+			require.NoError(t, err)
+			assert.Len(t, actual, expected, "query %v", q)
+			assert.True(t, sort.SliceIsSorted(actual, func(i, j int) bool {
+				return actual[i].DistanceKm < actual[j].DistanceKm
+			}))
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// prefixIndex is a sorted index over the folded names, aliases and UN/LOCODEs of the
+// stored ports. Every value is indexed from each of its word starts, so that "dha"
+// suggests "Abu Dhabi" as well. Entries sharing a prefix are adjacent, so a lookup
+// is a binary search followed by a scan of the matching entries only.
+type prefixIndex struct {
+	entries *sortedEntries
+}
+
+func newPrefixIndex() *prefixIndex {
+	return &prefixIndex{
+		entries: newSortedEntries(),
+	}
+}
+
+// prefixKey folds s and joins its tokens by single spaces.
+func prefixKey(s string) string {
+	return strings.Join(portsmanaging.Tokenize(s), " ")
+}
+
+// prefixKeysOf returns the distinct keys under which a port is indexed.
+func prefixKeysOf(p *portsmanaging.MaritimePort) []string {
+	values := make([]string, 0, 1+len(p.Alias)+len(p.Unlocs))
+	values = append(values, p.Name)
+	values = append(values, p.Alias...)
+	values = append(values, p.Unlocs...)
+
+	keys := make([]string, 0, len(values))
+
+	for _, v := range values {
+		tokens := portsmanaging.Tokenize(v)
+		for i := range tokens {
+			keys = append(keys, strings.Join(tokens[i:], " "))
+		}
+	}
+
+	sort.Strings(keys)
+
+	return compactSorted(keys)
+}
+
+func (x *prefixIndex) add(p *portsmanaging.MaritimePort) {
+	for _, key := range prefixKeysOf(p) {
+		x.entries.insert(indexEntry{key: key, id: p.ID})
+	}
+}
+
+func (x *prefixIndex) remove(p *portsmanaging.MaritimePort) {
+	for _, key := range prefixKeysOf(p) {
+		x.entries.delete(indexEntry{key: key, id: p.ID})
+	}
+}
+
+// lookup returns the IDs of up to limit distinct ports having a value with a word
+// starting with prefix, ordered by the matching value and then by ID.
+func (x *prefixIndex) lookup(prefix string, limit int) []string {
+	key := prefixKey(prefix)
+	if key == "" {
+		return []string{}
+	}
+
+	seen := make(idSet)
+	result := make([]string, 0, limit)
+
+	x.entries.ascend(indexEntry{key: key}, func(e indexEntry) bool {
+		if len(result) == limit || !strings.HasPrefix(e.key, key) {
+			return false
+		}
+
+		if _, ok := seen[e.id]; !ok {
+			seen[e.id] = struct{}{}
+			result = append(result, e.id)
+		}
+
+		return true
+	})
+
+	return result
+}
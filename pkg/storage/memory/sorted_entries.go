@@ -0,0 +1,125 @@
+package memory
+
+import "sort"
+
+// maxBlockSize is the number of entries above which a block of sortedEntries is split.
+const maxBlockSize = 256
+
+// indexEntry is an indexed key of a port value together with the ID of the port.
+type indexEntry struct {
+	key string
+	id  string
+}
+
+// less reports whether e is ordered before o, by key and then by ID.
+func (e indexEntry) less(o indexEntry) bool {
+	if e.key != o.key {
+		return e.key < o.key
+	}
+
+	return e.id < o.id
+}
+
+// sortedEntries is an ordered set of index entries. The entries are kept in sorted blocks
+// of at most maxBlockSize entries, so that inserting or deleting an entry only moves the
+// entries of its block rather than all entries after it, while entries can still be
+// scanned in order from the position of any entry found by binary search.
+type sortedEntries struct {
+	blocks [][]indexEntry
+}
+
+func newSortedEntries() *sortedEntries {
+	return &sortedEntries{
+		blocks: make([][]indexEntry, 0),
+	}
+}
+
+// search returns the position of the first entry not ordered before e, given by the index
+// of its block and its index within the block.
+func (s *sortedEntries) search(e indexEntry) (int, int) {
+	i := sort.Search(len(s.blocks), func(i int) bool {
+		block := s.blocks[i]
+
+		return !block[len(block)-1].less(e)
+	})
+
+	if i == len(s.blocks) {
+		return i, 0
+	}
+
+	block := s.blocks[i]
+
+	return i, sort.Search(len(block), func(j int) bool {
+		return !block[j].less(e)
+	})
+}
+
+// insert adds e unless it is already present.
+func (s *sortedEntries) insert(e indexEntry) {
+	i, j := s.search(e)
+	if i == len(s.blocks) {
+		if i == 0 {
+			s.blocks = append(s.blocks, []indexEntry{e})
+
+			return
+		}
+
+		i--
+		j = len(s.blocks[i])
+	}
+
+	block := s.blocks[i]
+	if j < len(block) && block[j] == e {
+		return
+	}
+
+	block = append(block, indexEntry{})
+	copy(block[j+1:], block[j:])
+	block[j] = e
+
+	if len(block) <= maxBlockSize {
+		s.blocks[i] = block
+
+		return
+	}
+
+	half := len(block) / 2
+	upper := make([]indexEntry, len(block)-half, maxBlockSize+1)
+	copy(upper, block[half:])
+
+	s.blocks = append(s.blocks, nil)
+	copy(s.blocks[i+2:], s.blocks[i+1:])
+	s.blocks[i] = block[:half]
+	s.blocks[i+1] = upper
+}
+
+// delete removes e if it is present.
+func (s *sortedEntries) delete(e indexEntry) {
+	i, j := s.search(e)
+	if i == len(s.blocks) || s.blocks[i][j] != e {
+		return
+	}
+
+	block := append(s.blocks[i][:j], s.blocks[i][j+1:]...)
+	if len(block) > 0 {
+		s.blocks[i] = block
+
+		return
+	}
+
+	s.blocks = append(s.blocks[:i], s.blocks[i+1:]...)
+}
+
+// ascend calls fn for the entries not ordered before from in ascending order, until fn
+// returns false.
+func (s *sortedEntries) ascend(from indexEntry, fn func(indexEntry) bool) {
+	i, j := s.search(from)
+
+	for ; i < len(s.blocks); i, j = i+1, 0 {
+		for _, e := range s.blocks[i][j:] {
+			if !fn(e) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package memory
+
+import (
+	"math"
+	"sort"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// spatialCellDeg is the size in degrees of the grid cells of the spatial index.
+const spatialCellDeg = 1.0
+
+// geoPoint is a location in degrees.
+type geoPoint struct {
+	lat float64
+	lon float64
+}
+
+// cellBounds represents the latitude and longitude ranges in degrees covered by a grid cell.
+type cellBounds struct {
+	minLat, maxLat float64
+	minLon, maxLon float64
+}
+
+// neighbour is a port ID together with its distance to a queried location.
+type neighbour struct {
+	id         string
+	distanceKm float64
+}
+
+// spatialIndex is a grid index over port locations. The grid splits the globe into
+// cells of spatialCellDeg x spatialCellDeg degrees, only non-empty cells being stored.
+// Cells are grouped into latitude rows so that queries can visit rows in order of
+// their latitude distance and stop as soon as no closer port can be found.
+type spatialIndex struct {
+	rows   map[int]map[int]idSet
+	points map[string]geoPoint
+}
+
+func newSpatialIndex() *spatialIndex {
+	return &spatialIndex{
+		rows:   make(map[int]map[int]idSet),
+		points: make(map[string]geoPoint),
+	}
+}
+
+func cellOf(lat float64, lon float64) (int, int) {
+	row := int(math.Floor((lat + 90) / spatialCellDeg))
+	col := int(math.Floor((lon + 180) / spatialCellDeg))
+
+	// Points on the north pole and on the antimeridian belong to the last row and column.
+	if row >= numRows() {
+		row = numRows() - 1
+	}
+
+	if col >= numCols() {
+		col = numCols() - 1
+	}
+
+	return row, col
+}
+
+func numRows() int {
+	return int(180 / spatialCellDeg)
+}
+
+func numCols() int {
+	return int(360 / spatialCellDeg)
+}
+
+func boundsOf(row int, col int) cellBounds {
+	return cellBounds{
+		minLat: float64(row)*spatialCellDeg - 90,
+		maxLat: float64(row+1)*spatialCellDeg - 90,
+		minLon: float64(col)*spatialCellDeg - 180,
+		maxLon: float64(col+1)*spatialCellDeg - 180,
+	}
+}
+
+func (s *spatialIndex) add(p *portsmanaging.MaritimePort) {
+	lat, lon, ok := p.Location()
+	if !ok {
+		return
+	}
+
+	row, col := cellOf(lat, lon)
+
+	cols, ok := s.rows[row]
+	if !ok {
+		cols = make(map[int]idSet)
+		s.rows[row] = cols
+	}
+
+	ids, ok := cols[col]
+	if !ok {
+		ids = make(idSet)
+		cols[col] = ids
+	}
+
+	ids[p.ID] = struct{}{}
+	s.points[p.ID] = geoPoint{lat: lat, lon: lon}
+}
+
+func (s *spatialIndex) remove(p *portsmanaging.MaritimePort) {
+	pt, ok := s.points[p.ID]
+	if !ok {
+		return
+	}
+
+	delete(s.points, p.ID)
+
+	row, col := cellOf(pt.lat, pt.lon)
+	cols := s.rows[row]
+	ids := cols[col]
+
+	delete(ids, p.ID)
+
+	if len(ids) == 0 {
+		delete(cols, col)
+	}
+
+	if len(cols) == 0 {
+		delete(s.rows, row)
+	}
+}
+
+// nearest returns up to k ports closest to the given location and not farther than
+// maxKm kilometers (0 means no limit), ordered by ascending distance.
+func (s *spatialIndex) nearest(lat float64, lon float64, k int, maxKm float64) []neighbour {
+	result := make([]neighbour, 0, k)
+
+	// threshold is the distance beyond which ports cannot make it into the result.
+	threshold := func() float64 {
+		t := math.Inf(1)
+		if maxKm > 0 {
+			t = maxKm
+		}
+
+		if len(result) == k && result[k-1].distanceKm < t {
+			t = result[k-1].distanceKm
+		}
+
+		return t
+	}
+
+	s.visitRows(lat, threshold, func(row int, cols map[int]idSet) {
+		for col, ids := range cols {
+			if minDistanceToCellKm(lat, lon, boundsOf(row, col)) > threshold() {
+				continue
+			}
+
+			for id := range ids {
+				pt := s.points[id]
+
+				d := portsmanaging.HaversineKm(lat, lon, pt.lat, pt.lon)
+				if d > threshold() {
+					continue
+				}
+
+				result = insertNeighbour(result, neighbour{id: id, distanceKm: d}, k)
+			}
+		}
+	})
+
+	return result
+}
+
+// withinRadius returns all ports not farther than radiusKm kilometers from the given
+// location, ordered by ascending distance.
+func (s *spatialIndex) withinRadius(lat float64, lon float64, radiusKm float64) []neighbour {
+	result := make([]neighbour, 0)
+
+	threshold := func() float64 {
+		return radiusKm
+	}
+
+	s.visitRows(lat, threshold, func(row int, cols map[int]idSet) {
+		for col, ids := range cols {
+			if minDistanceToCellKm(lat, lon, boundsOf(row, col)) > radiusKm {
+				continue
+			}
+
+			for id := range ids {
+				pt := s.points[id]
+
+				if d := portsmanaging.HaversineKm(lat, lon, pt.lat, pt.lon); d <= radiusKm {
+					result = append(result, neighbour{id: id, distanceKm: d})
+				}
+			}
+		}
+	})
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].distanceKm != result[j].distanceKm {
+			return result[i].distanceKm < result[j].distanceKm
+		}
+
+		return result[i].id < result[j].id
+	})
+
+	return result
+}
+
+// withinBox returns the IDs of all ports inside the bounding box, ordered by ID.
+// A box crossing the antimeridian is split into two column ranges, one on each side.
+func (s *spatialIndex) withinBox(box portsmanaging.BoundingBox) []string {
+	lonRanges := [][2]float64{{box.MinLon, box.MaxLon}}
+	if box.CrossesAntimeridian() {
+		lonRanges = [][2]float64{{box.MinLon, 180}, {-180, box.MaxLon}}
+	}
+
+	minRow, _ := cellOf(box.MinLat, 0)
+	maxRow, _ := cellOf(box.MaxLat, 0)
+	result := make([]string, 0)
+
+	for row := minRow; row <= maxRow; row++ {
+		cols, ok := s.rows[row]
+		if !ok {
+			continue
+		}
+
+		for _, lonRange := range lonRanges {
+			_, minCol := cellOf(0, lonRange[0])
+			_, maxCol := cellOf(0, lonRange[1])
+
+			for col := minCol; col <= maxCol; col++ {
+				for id := range cols[col] {
+					pt := s.points[id]
+
+					if box.Contains(pt.lat, pt.lon) {
+						result = append(result, id)
+					}
+				}
+			}
+		}
+	}
+
+	// A port on the antimeridian may belong to both column ranges of a crossing box.
+	sort.Strings(result)
+
+	return compactSorted(result)
+}
+
+// compactSorted removes consecutive duplicates from a sorted slice.
+func compactSorted(ids []string) []string {
+	if len(ids) == 0 {
+		return ids
+	}
+
+	out := ids[:1]
+
+	for _, id := range ids[1:] {
+		if id != out[len(out)-1] {
+			out = append(out, id)
+		}
+	}
+
+	return out
+}
+
+// visitRows calls visit for every non-empty row, starting with the row of lat and moving
+// outwards in both directions, until the rows are farther than the threshold.
+func (s *spatialIndex) visitRows(lat float64, threshold func() float64, visit func(row int, cols map[int]idSet)) {
+	row, _ := cellOf(lat, 0)
+	kmPerRow := toRadians(spatialCellDeg) * portsmanaging.EarthRadiusKm
+
+	for dr := 0; dr < numRows(); dr++ {
+		// Any location in a row dr rows away is at least dr-1 full rows away in latitude.
+		if dr > 1 && float64(dr-1)*kmPerRow > threshold() {
+			return
+		}
+
+		if cols, ok := s.rows[row-dr]; ok {
+			visit(row-dr, cols)
+		}
+
+		if cols, ok := s.rows[row+dr]; ok && dr > 0 {
+			visit(row+dr, cols)
+		}
+	}
+}
+
+// insertNeighbour inserts n into the distance ordered result, keeping at most k entries.
+// Ties on the distance are broken by port ID to keep the result deterministic.
+func insertNeighbour(result []neighbour, n neighbour, k int) []neighbour {
+	i := sort.Search(len(result), func(i int) bool {
+		if result[i].distanceKm != n.distanceKm {
+			return result[i].distanceKm > n.distanceKm
+		}
+
+		return result[i].id > n.id
+	})
+
+	if i >= k {
+		return result
+	}
+
+	if len(result) < k {
+		result = append(result, neighbour{})
+	}
+
+	copy(result[i+1:], result[i:])
+	result[i] = n
+
+	return result
+}
+
+// minDistanceToCellKm returns the great-circle distance from a location to the closest
+// point of a grid cell. For a location within the longitude range of the cell the closest
+// point lies on the same meridian. Otherwise it lies on the nearer of the two meridian edges
+// of the cell, as distances along a parallel grow with the longitude difference.
+func minDistanceToCellKm(lat float64, lon float64, b cellBounds) float64 {
+	if lon >= b.minLon && lon <= b.maxLon {
+		dLat := math.Max(0, math.Max(b.minLat-lat, lat-b.maxLat))
+
+		return toRadians(dLat) * portsmanaging.EarthRadiusKm
+	}
+
+	return math.Min(
+		minDistanceToMeridianKm(lat, lon, b.minLon, b.minLat, b.maxLat),
+		minDistanceToMeridianKm(lat, lon, b.maxLon, b.minLat, b.maxLat),
+	)
+}
+
+// minDistanceToMeridianKm returns the great-circle distance from a location to the closest
+// point of the meridian segment at longitude edgeLon between minLat and maxLat.
+func minDistanceToMeridianKm(lat float64, lon float64, edgeLon float64, minLat float64, maxLat float64) float64 {
+	dLon := math.Abs(lonDiff(edgeLon, lon))
+
+	if dLon < 90 {
+		// Foot of the perpendicular from the location onto the meridian great circle,
+		// along which the distance grows monotonically in both directions.
+		footLat := toDegrees(math.Atan(math.Tan(toRadians(lat)) / math.Cos(toRadians(dLon))))
+		footLat = math.Max(minLat, math.Min(maxLat, footLat))
+
+		return portsmanaging.HaversineKm(lat, lon, footLat, edgeLon)
+	}
+
+	// On a meridian facing away from the location the distance is largest
+	// in between the poles, so the closest point is one of the segment ends.
+	return math.Min(
+		portsmanaging.HaversineKm(lat, lon, minLat, edgeLon),
+		portsmanaging.HaversineKm(lat, lon, maxLat, edgeLon),
+	)
+}
+
+// lonDiff returns the signed difference a-b normalized to [-180, 180).
+func lonDiff(a float64, b float64) float64 {
+	return math.Mod(math.Mod(a-b+180, 360)+360, 360) - 180
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func toDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
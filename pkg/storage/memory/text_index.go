@@ -0,0 +1,278 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+)
+
+// Field weights express how relevant a term is depending on where it occurs in a port.
+const (
+	nameWeight     = 3.0
+	unlocWeight    = 3.0
+	aliasWeight    = 2.0
+	cityWeight     = 2.0
+	provinceWeight = 1.0
+)
+
+// Match scores express how well an indexed term matches a searched term.
+const (
+	exactMatchScore  = 1.0
+	prefixMatchScore = 0.8
+	typoMatchScore   = 0.7
+	typoPenalty      = 0.2
+	minPrefixLength  = 2
+	// maxTypos is the largest number of typos tolerated by portsmanaging.MaxEditDistance.
+	maxTypos = 2
+)
+
+// scoredID is a port ID together with its relevance to a searched text.
+type scoredID struct {
+	id    string
+	score float64
+}
+
+// textIndex is an inverted index mapping the folded tokens of the searchable port fields
+// to the IDs of the ports containing them, together with the weight of the most relevant
+// field each token occurs in.
+//
+// The indexed terms are also kept in sorted order, so that the terms starting with a
+// searched one are found by a binary search, and by their deletion neighbourhood, i.e.
+// all strings obtained by deleting up to maxTypos runes from them. Two terms within an
+// edit distance of n share a string obtained by deleting up to n runes from each, so
+// the terms a searched one may be a typo of are found by looking up its own deletion
+// neighbourhood, rather than by computing its edit distance to every indexed term.
+type textIndex struct {
+	postings   map[string]map[string]float64
+	terms      *sortedEntries
+	neighbours map[string]map[string]struct{}
+}
+
+func newTextIndex() *textIndex {
+	return &textIndex{
+		postings:   make(map[string]map[string]float64),
+		terms:      newSortedEntries(),
+		neighbours: make(map[string]map[string]struct{}),
+	}
+}
+
+// deletions returns the distinct strings obtained by deleting up to n runes from term,
+// including term itself.
+func deletions(term string, n int) []string {
+	result := []string{term}
+	seen := map[string]struct{}{term: {}}
+	level := result
+
+	for ; n > 0; n-- {
+		next := make([]string, 0)
+
+		for _, s := range level {
+			runes := []rune(s)
+
+			for i := range runes {
+				d := string(runes[:i]) + string(runes[i+1:])
+				if _, ok := seen[d]; ok {
+					continue
+				}
+
+				seen[d] = struct{}{}
+				next = append(next, d)
+			}
+		}
+
+		result = append(result, next...)
+		level = next
+	}
+
+	return result
+}
+
+// termsOf returns the searchable terms of a port mapped to their field weights.
+func termsOf(p *portsmanaging.MaritimePort) map[string]float64 {
+	terms := make(map[string]float64)
+
+	addTerms := func(text string, weight float64) {
+		for _, t := range portsmanaging.Tokenize(text) {
+			if weight > terms[t] {
+				terms[t] = weight
+			}
+		}
+	}
+
+	addTerms(p.Name, nameWeight)
+	addTerms(p.City, cityWeight)
+	addTerms(p.Province, provinceWeight)
+
+	for _, a := range p.Alias {
+		addTerms(a, aliasWeight)
+	}
+
+	for _, u := range p.Unlocs {
+		addTerms(u, unlocWeight)
+	}
+
+	return terms
+}
+
+func (t *textIndex) add(p *portsmanaging.MaritimePort) {
+	for term, weight := range termsOf(p) {
+		ids, ok := t.postings[term]
+		if !ok {
+			ids = make(map[string]float64)
+			t.postings[term] = ids
+			t.addTerm(term)
+		}
+
+		ids[p.ID] = weight
+	}
+}
+
+func (t *textIndex) remove(p *portsmanaging.MaritimePort) {
+	for term := range termsOf(p) {
+		ids, ok := t.postings[term]
+		if !ok {
+			continue
+		}
+
+		delete(ids, p.ID)
+
+		if len(ids) == 0 {
+			delete(t.postings, term)
+			t.removeTerm(term)
+		}
+	}
+}
+
+func (t *textIndex) addTerm(term string) {
+	t.terms.insert(indexEntry{key: term})
+
+	for _, d := range deletions(term, maxTypos) {
+		terms, ok := t.neighbours[d]
+		if !ok {
+			terms = make(map[string]struct{})
+			t.neighbours[d] = terms
+		}
+
+		terms[term] = struct{}{}
+	}
+}
+
+func (t *textIndex) removeTerm(term string) {
+	t.terms.delete(indexEntry{key: term})
+
+	for _, d := range deletions(term, maxTypos) {
+		delete(t.neighbours[d], term)
+
+		if len(t.neighbours[d]) == 0 {
+			delete(t.neighbours, d)
+		}
+	}
+}
+
+// candidates returns the indexed terms which may match the searched one, i.e. the term
+// itself, the terms it is a prefix of and those it may be a typo of.
+func (t *textIndex) candidates(searched string) map[string]struct{} {
+	result := make(map[string]struct{})
+
+	if len(searched) >= minPrefixLength {
+		t.terms.ascend(indexEntry{key: searched}, func(e indexEntry) bool {
+			if !strings.HasPrefix(e.key, searched) {
+				return false
+			}
+
+			result[e.key] = struct{}{}
+
+			return true
+		})
+	} else if _, ok := t.postings[searched]; ok {
+		result[searched] = struct{}{}
+	}
+
+	for _, d := range deletions(searched, portsmanaging.MaxEditDistance(searched)) {
+		for term := range t.neighbours[d] {
+			result[term] = struct{}{}
+		}
+	}
+
+	return result
+}
+
+// matchScore returns how well the indexed term matches the searched one: exactly,
+// as a prefix or within the tolerated number of typos. Zero means no match.
+func matchScore(searched string, term string) float64 {
+	if term == searched {
+		return exactMatchScore
+	}
+
+	if len(searched) >= minPrefixLength && strings.HasPrefix(term, searched) {
+		return prefixMatchScore
+	}
+
+	maxDistance := portsmanaging.MaxEditDistance(searched)
+	if maxDistance == 0 {
+		return 0
+	}
+
+	if d := portsmanaging.EditDistance(searched, term, maxDistance); d <= maxDistance {
+		return typoMatchScore - float64(d-1)*typoPenalty
+	}
+
+	return 0
+}
+
+// search returns up to limit ports matching the text ordered by descending score.
+// A port scores the sum over the searched terms of its best weighted match, scaled
+// by the fraction of searched terms it matches, so ports matching all terms rank first.
+func (t *textIndex) search(text string, limit int) []scoredID {
+	searched := portsmanaging.Tokenize(text)
+	sort.Strings(searched)
+	searched = compactSorted(searched)
+
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+
+	for _, s := range searched {
+		best := make(map[string]float64)
+
+		for term := range t.candidates(s) {
+			m := matchScore(s, term)
+			if m == 0 {
+				continue
+			}
+
+			for id, weight := range t.postings[term] {
+				if m*weight > best[id] {
+					best[id] = m * weight
+				}
+			}
+		}
+
+		for id, score := range best {
+			scores[id] += score
+			matched[id]++
+		}
+	}
+
+	result := make([]scoredID, 0, len(scores))
+
+	for id, score := range scores {
+		score *= float64(matched[id]) / float64(len(searched))
+		result = append(result, scoredID{id: id, score: math.Round(score*1000) / 1000})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].score != result[j].score {
+			return result[i].score > result[j].score
+		}
+
+		return result[i].id < result[j].id
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result
+}
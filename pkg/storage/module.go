@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/powerslider/maritime-ports-service/pkg/configs"
+	"github.com/powerslider/maritime-ports-service/pkg/portsmanaging"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/file"
+	"github.com/powerslider/maritime-ports-service/pkg/storage/memory"
+)
+
+// SeedMarker is implemented by persistent stores, which remember whether they have been
+// seeded completely so that they are seeded only once.
+type SeedMarker interface {
+	Seeded() (bool, error)
+	MarkSeeded() error
+}
+
+// InitializePortsStore wires the portsmanaging.PortsStore implementation selected by config.
+// The returned close function releases all resources held by the store.
+func InitializePortsStore(config *configs.Config) (portsmanaging.PortsStore, func() error, error) {
+	switch config.Storage.Type {
+	case configs.StorageTypeMemory, "":
+		return memory.NewPortsRepository(), func() error { return nil }, nil
+	case configs.StorageTypeFile:
+		repo, err := file.NewPortsRepository(config.Storage.Dir, config.Storage.SnapshotThreshold)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return repo, repo.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported storage type '%s'", config.Storage.Type)
+	}
+}